@@ -0,0 +1,137 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package encodedbytes
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidText is returned when encoded text is not valid in its
+	// encoding
+	ErrInvalidText = errors.New("encodedbytes: invalid text")
+
+	// ErrUnrepresentable is returned when text contains a character that
+	// cannot be represented in the target encoding
+	ErrUnrepresentable = errors.New("encodedbytes: character cannot be represented")
+)
+
+// Converter converts strings between an encoding and UTF-8, which is the
+// native encoding for strings
+type Converter struct {
+	convert func(string) (string, error)
+}
+
+func (c *Converter) ConvertString(s string) (string, error) {
+	return c.convert(s)
+}
+
+func decodeISO88591(s string) (string, error) {
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+
+	return string(runes), nil
+}
+
+func encodeISO88591(s string) (string, error) {
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("%w: not UTF-8", ErrInvalidText)
+	}
+
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xff {
+			return "", fmt.Errorf("%w: %q in ISO-8859-1", ErrUnrepresentable, r)
+		}
+
+		b = append(b, byte(r))
+	}
+
+	return string(b), nil
+}
+
+// Decodes UTF-16 with a byte order mark, defaulting to big endian when there
+// is none
+func decodeUTF16(s string) (string, error) {
+	switch {
+	case len(s) >= 2 && s[0] == 0xff && s[1] == 0xfe:
+		return decodeUTF16Units(s[2:], false)
+	case len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff:
+		return decodeUTF16Units(s[2:], true)
+	}
+
+	return decodeUTF16Units(s, true)
+}
+
+// Encodes little endian UTF-16 with a byte order mark
+func encodeUTF16(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	b, err := encodeUTF16Units(s, false)
+	if err != nil {
+		return "", err
+	}
+
+	return "\xff\xfe" + b, nil
+}
+
+func decodeUTF16BE(s string) (string, error) {
+	return decodeUTF16Units(s, true)
+}
+
+func encodeUTF16BE(s string) (string, error) {
+	return encodeUTF16Units(s, true)
+}
+
+func decodeUTF16Units(s string, bigEndian bool) (string, error) {
+	if len(s)%2 != 0 {
+		return "", fmt.Errorf("%w: odd number of bytes in UTF-16", ErrInvalidText)
+	}
+
+	units := make([]uint16, len(s)/2)
+	for i := range units {
+		hi, lo := s[2*i], s[2*i+1]
+		if !bigEndian {
+			hi, lo = lo, hi
+		}
+
+		units[i] = uint16(hi)<<8 | uint16(lo)
+	}
+
+	return string(utf16.Decode(units)), nil
+}
+
+func encodeUTF16Units(s string, bigEndian bool) (string, error) {
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("%w: not UTF-8", ErrInvalidText)
+	}
+
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		if bigEndian {
+			b = append(b, byte(u>>8), byte(u))
+		} else {
+			b = append(b, byte(u), byte(u>>8))
+		}
+	}
+
+	return string(b), nil
+}
+
+// UTF-8 is the native encoding, so converting it only checks that it is valid
+func convertUTF8(s string) (string, error) {
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("%w: not UTF-8", ErrInvalidText)
+	}
+
+	return s, nil
+}
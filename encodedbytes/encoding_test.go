@@ -0,0 +1,50 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package encodedbytes
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConverters(t *testing.T) {
+	const text = "Nice Life ü"
+	encoded := []string{
+		"Nice Life \xfc",
+		"\xff\xfeN\x00i\x00c\x00e\x00 \x00L\x00i\x00f\x00e\x00 \x00\xfc\x00",
+		"\x00N\x00i\x00c\x00e\x00 \x00L\x00i\x00f\x00e\x00 \x00\xfc",
+		text,
+	}
+
+	for i, e := range encoded {
+		name := EncodingForIndex(byte(i))
+
+		if s, err := Encoders[i].ConvertString(text); err != nil || s != e {
+			t.Errorf("%s encoder = %q with error %v, want %q", name, s, err, e)
+		}
+
+		if s, err := Decoders[i].ConvertString(e); err != nil || s != text {
+			t.Errorf("%s decoder = %q with error %v, want %q", name, s, err, text)
+		}
+	}
+
+	// Big endian UTF-16 with a byte order mark
+	if s, err := Decoders[1].ConvertString("\xfe\xff\x00N\x00i"); err != nil || s != "Ni" {
+		t.Errorf("UTF-16 decoder = %q with error %v, want %q", s, err, "Ni")
+	}
+}
+
+func TestConverterErrors(t *testing.T) {
+	if _, err := Encoders[0].ConvertString("Nice Life ☺"); !errors.Is(err, ErrUnrepresentable) {
+		t.Errorf("ISO-8859-1 encoder returned %v for unrepresentable character", err)
+	}
+
+	if _, err := Decoders[2].ConvertString("\x00N\x00"); !errors.Is(err, ErrInvalidText) {
+		t.Errorf("UTF-16BE decoder returned %v for odd length", err)
+	}
+
+	if _, err := Decoders[3].ConvertString("Nice \xff"); !errors.Is(err, ErrInvalidText) {
+		t.Errorf("UTF-8 decoder returned %v for invalid text", err)
+	}
+}
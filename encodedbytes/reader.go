@@ -84,4 +84,13 @@ func (r *Reader) ReadNullTermString(encoding byte) (string, error) {
 	return Decoders[encoding].ConvertString(string(b[:atIndex]))
 }
 
+// Number of bytes that have not been read
+func (r *Reader) Len() int {
+	if r.index >= len(r.data) {
+		return 0
+	}
+
+	return len(r.data) - r.index
+}
+
 func NewReader(b []byte) *Reader { return &Reader{b, 0} }
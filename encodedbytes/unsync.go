@@ -0,0 +1,37 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package encodedbytes
+
+// Apply the unsynchronization scheme, which inserts a zero byte after every
+// 0xFF byte that could be mistaken for the start of an MPEG frame sync or
+// for an inserted zero byte
+func Unsynchronize(b []byte) []byte {
+	data := make([]byte, 0, len(b))
+
+	for i, c := range b {
+		data = append(data, c)
+
+		if c == 0xff && (i == len(b)-1 || b[i+1] >= 0xe0 || b[i+1] == 0x00) {
+			data = append(data, 0x00)
+		}
+	}
+
+	return data
+}
+
+// Reverse the unsynchronization scheme by removing the zero byte after every
+// 0xFF byte
+func Resynchronize(b []byte) []byte {
+	data := make([]byte, 0, len(b))
+
+	for i := 0; i < len(b); i++ {
+		data = append(data, b[i])
+
+		if b[i] == 0xff && i+1 < len(b) && b[i+1] == 0x00 {
+			i++
+		}
+	}
+
+	return data
+}
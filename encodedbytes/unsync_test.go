@@ -0,0 +1,21 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package encodedbytes
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUnsynchronize(t *testing.T) {
+	synch := []byte{0xff, 0xfb, 0x12, 0xff, 0x00, 0xff, 0x34, 0xff}
+	unsynch := []byte{0xff, 0x00, 0xfb, 0x12, 0xff, 0x00, 0x00, 0xff, 0x34, 0xff, 0x00}
+
+	if result := Unsynchronize(synch); !bytes.Equal(result, unsynch) {
+		t.Errorf("encodedbytes.Unsynchronize(%v) = %v, want %v", synch, result, unsynch)
+	}
+	if result := Resynchronize(unsynch); !bytes.Equal(result, synch) {
+		t.Errorf("encodedbytes.Resynchronize(%v) = %v, want %v", unsynch, result, synch)
+	}
+}
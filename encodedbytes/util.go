@@ -6,7 +6,6 @@ package encodedbytes
 import (
 	"bytes"
 	"errors"
-	iconv "github.com/djimenez/iconv-go"
 )
 
 const (
@@ -28,17 +27,22 @@ var (
 		{Name: "UTF-16BE", NullLength: 2},
 		{Name: "UTF-8", NullLength: 1},
 	}
-	Decoders = make([]*iconv.Converter, len(EncodingMap))
-	Encoders = make([]*iconv.Converter, len(EncodingMap))
-)
 
-func init() {
-	n := EncodingForIndex(NativeEncoding)
-	for i, e := range EncodingMap {
-		Decoders[i], _ = iconv.NewConverter(e.Name, n)
-		Encoders[i], _ = iconv.NewConverter(n, e.Name)
+	// Converters from each encoding to the native encoding and back, in the
+	// order of EncodingMap
+	Decoders = []*Converter{
+		{decodeISO88591},
+		{decodeUTF16},
+		{decodeUTF16BE},
+		{convertUTF8},
 	}
-}
+	Encoders = []*Converter{
+		{encodeISO88591},
+		{encodeUTF16},
+		{encodeUTF16BE},
+		{convertUTF8},
+	}
+)
 
 // Form an integer from concatenated bits
 func ByteInt(buf []byte, base uint) (i uint32, err error) {
@@ -92,7 +96,7 @@ func NormBytes(n uint32) []byte {
 
 func EncodingForIndex(b byte) string {
 	encodingIndex := int(b)
-	if encodingIndex < 0 || encodingIndex > len(EncodingMap) {
+	if encodingIndex < 0 || encodingIndex >= len(EncodingMap) {
 		encodingIndex = 0
 	}
 
@@ -101,7 +105,7 @@ func EncodingForIndex(b byte) string {
 
 func EncodingNullLengthForIndex(b byte) int {
 	encodingIndex := int(b)
-	if encodingIndex < 0 || encodingIndex > len(EncodingMap) {
+	if encodingIndex < 0 || encodingIndex >= len(EncodingMap) {
 		encodingIndex = 0
 	}
 
@@ -43,7 +43,11 @@ type Tagger interface {
 type File struct {
 	Tagger
 	originalSize int
-	file         *os.File
+	// Offset of the audio that follows a v2 tag
+	audioStart int64
+	// Tag as it was last saved, or nil if the tag has not been saved
+	saved []byte
+	file  *os.File
 }
 
 // Parses an open file
@@ -51,8 +55,7 @@ func Parse(file *os.File) (*File, error) {
 	res := &File{file: file}
 
 	if v2Tag := v2.ParseTag(file); v2Tag != nil {
-		res.Tagger = v2Tag
-		res.originalSize = v2Tag.Size()
+		res.setV2Tag(v2Tag)
 	} else if v1Tag := v1.ParseTag(file); v1Tag != nil {
 		res.Tagger = v1Tag
 	} else {
@@ -63,6 +66,54 @@ func Parse(file *os.File) (*File, error) {
 	return res, nil
 }
 
+// Reads an open file, returning an error if a tag is present but cannot be
+// read
+// As with Parse, a new tag is added if the file has no tag
+func Read(file *os.File) (*File, error) {
+	res := &File{file: file}
+
+	v2Tag, err := v2.ReadTag(file)
+	if err == nil {
+		res.setV2Tag(v2Tag)
+		return res, nil
+	} else if !errors.Is(err, v2.ErrNoTag) {
+		return nil, err
+	}
+
+	v1Tag, err := v1.ReadTag(file)
+	if err == nil {
+		res.Tagger = v1Tag
+		return res, nil
+	} else if !errors.Is(err, v1.ErrNoTag) {
+		return nil, err
+	}
+
+	// Add a new tag if none exists
+	res.Tagger = v2.NewTag(LatestVersion)
+
+	return res, nil
+}
+
+func (f *File) setV2Tag(tag *v2.Tag) {
+	f.Tagger = tag
+	f.originalSize = tag.Header.Size()
+	f.audioStart = int64(v2.HeaderSize + tag.Header.Size())
+	if tag.Header.Footer() {
+		f.audioStart += v2.FooterSize
+	}
+}
+
+// Reads the tags that the seek frames of the v2 tag point to, such as a tag
+// appended to the end of the file
+func (f *File) SeekTags() ([]*v2.Tag, error) {
+	tag, ok := f.Tagger.(*v2.Tag)
+	if !ok {
+		return nil, nil
+	}
+
+	return v2.ReadSeekTags(f.file, tag, f.audioStart)
+}
+
 // Opens a new tagged file
 func Open(name string) (*File, error) {
 	fi, err := os.OpenFile(name, os.O_RDWR, 0666)
@@ -78,39 +129,16 @@ func Open(name string) (*File, error) {
 	return file, nil
 }
 
-// Saves any edits to the tagged file
+// Saves any edits to the tagged file and closes it
+// The tag is written in place when it fits in the space of the original tag,
+// otherwise the file is rewritten as with Save
 func (f *File) Close() error {
-	defer f.file.Close()
-
-	if !f.Dirty() {
-		return nil
-	}
-
-	switch f.Tagger.(type) {
-	case (*v1.Tag):
-		if _, err := f.file.Seek(-v1.TagSize, os.SEEK_END); err != nil {
-			return err
-		}
-	case (*v2.Tag):
-		if f.Size() > f.originalSize {
-			start := int64(f.originalSize + v2.HeaderSize)
-			offset := int64(f.Tagger.Size() - f.originalSize)
-
-			if err := shiftBytesBack(f.file, start, offset); err != nil {
-				return err
-			}
-		}
-
-		if _, err := f.file.Seek(0, os.SEEK_SET); err != nil {
-			return err
-		}
-	default:
-		return errors.New("Close: unknown tag version")
-	}
+	err := f.Save(SaveOptions{InPlace: true})
 
-	if _, err := f.file.Write(f.Tagger.Bytes()); err != nil {
-		return err
+	// Saving can replace the file
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
 	}
 
-	return nil
+	return err
 }
@@ -5,11 +5,15 @@ package id3
 
 import (
 	"bytes"
+	"errors"
 	v2 "github.com/mikkyang/id3-go/v2"
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -275,3 +279,264 @@ func TestUTF16CommPanic(t *testing.T) {
 		file.Close()
 	}
 }
+
+func TestRead(t *testing.T) {
+	file, err := os.Open(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	tagger, err := Read(file)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	if s := tagger.Title(); s != "Nice Life (Feat. Basick)" {
+		t.Errorf("Read: incorrect title, %v", s)
+	}
+}
+
+func TestSave(t *testing.T) {
+	before, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "save")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, testFile)
+	if err := ioutil.WriteFile(name, before, 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	modTime := time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(name, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	beforeInfo, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audioStart := file.audioStart
+
+	// A short title fits in the padding, so the file is written in place
+	file.SetTitle("Nice")
+	if err := file.Save(SaveOptions{InPlace: true, PreserveModTime: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !os.SameFile(beforeInfo, info) {
+		t.Error("Save did not write tag in place")
+	}
+
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("Save did not preserve modification time, %v", info.ModTime())
+	}
+
+	// Without in place writes, the file is replaced
+	file.SetTitle(strings.Repeat("Nice Life ", 1000))
+	if err := file.Save(SaveOptions{PreserveModTime: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err = os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if os.SameFile(beforeInfo, info) {
+		t.Error("Save wrote tag in place")
+	}
+
+	if perm := info.Mode().Perm(); perm != 0640 {
+		t.Errorf("Save did not preserve permissions, %v", perm)
+	}
+
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("Save did not preserve modification time, %v", info.ModTime())
+	}
+
+	after, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(before[audioStart:], after[file.audioStart:]) {
+		t.Error("Save lost nontag data")
+	}
+
+	if entries, err := ioutil.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("Save left temporary files, %v", entries)
+	}
+
+	reopened, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if s := reopened.Title(); s != strings.Repeat("Nice Life ", 1000) {
+		t.Errorf("Save incorrect title, %v", s)
+	}
+}
+
+func TestSavePadding(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "padding")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	file, err := Open(tempFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file.SetTitle(strings.Repeat("Nice Life ", 1000))
+	if err := file.Save(SaveOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file shrinks once the tag has too much padding
+	file.SetTitle("Nice Life")
+	padding := &v2.PaddingPolicy{Min: 0, Target: 1024, Max: 4096}
+	if err := file.Save(SaveOptions{InPlace: true, Padding: padding}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(tempFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size := int(info.Size()); size != v2.HeaderSize+file.Tagger.Size() || file.Padding() != 1024 {
+		t.Errorf("Save did not shrink file, %d bytes with %d bytes of padding", size, file.Padding())
+	}
+}
+
+func TestSaveStripPadding(t *testing.T) {
+	before, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tempFile, err := ioutil.TempFile("", "strip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(before); err != nil {
+		t.Fatal(err)
+	}
+	tempFile.Close()
+
+	file, err := Open(tempFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if file.Padding() == 0 {
+		t.Fatal("test file has no padding to strip")
+	}
+
+	// Stripping the padding of an unmodified file still saves it
+	if err := file.Save(SaveOptions{Padding: &v2.PaddingPolicy{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(tempFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if padding := reopened.Padding(); padding != 0 {
+		t.Errorf("Save did not strip padding, %d bytes left", padding)
+	}
+}
+
+type failingCipher struct{}
+
+func (failingCipher) Encrypt(data []byte) ([]byte, error) {
+	return nil, errors.New("no key")
+}
+
+func (failingCipher) Decrypt(data []byte) ([]byte, error) {
+	return nil, errors.New("no key")
+}
+
+func TestSaveEncryptionFailure(t *testing.T) {
+	v2.RegisterFrameCipher("owner", failingCipher{})
+	defer v2.RegisterFrameCipher("owner", nil)
+
+	before, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tempFile, err := ioutil.TempFile("", "encrypt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(before); err != nil {
+		t.Fatal(err)
+	}
+	tempFile.Close()
+
+	file, err := Open(tempFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	file.AddFrames(v2.NewEncryptionMethodFrame(v2.V23FrameTypeMap["ENCR"], "owner", 0x80, nil))
+	file.SetTitle("Secret")
+	if err := file.Frame("TIT2").SetEncryptionMethod(0x80, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// The frame is not dropped from the file
+	if err := file.Save(SaveOptions{}); err == nil {
+		t.Error("Save succeeded with a frame that cannot be encrypted")
+	}
+
+	after, err := ioutil.ReadFile(tempFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(before, after) {
+		t.Error("failed Save changed the file")
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package id3
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"github.com/mikkyang/id3-go/v1"
+	"github.com/mikkyang/id3-go/v2"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SaveOptions controls how a tagged file is saved
+type SaveOptions struct {
+	// Write the tag over the original tag when it fits in the space of the
+	// original tag, which avoids copying the audio of large files
+	// A v2 tag fits when its padding takes up the change in size
+	InPlace bool
+	// Keep the modification time of the file
+	PreserveModTime bool
+	// Padding policy applied to a v2 tag before it is saved, which can grow
+	// the padding to make room for later edits, or shrink the file when the
+	// tag has become much smaller
+	Padding *v2.PaddingPolicy
+}
+
+// Saves any edits to the tagged file
+// Unless the tag is written in place, the tag and the audio are written to a
+// temporary file next to the file, which then replaces the file, so that a
+// failure never leaves the file partly written
+// The permissions of the file are kept, as is its ownership where the
+// platform and the user running the process allow it
+func (f *File) Save(opts SaveOptions) error {
+	// Applying the padding policy can change an unmodified tag
+	if v2Tag, ok := f.Tagger.(*v2.Tag); ok && opts.Padding != nil {
+		if err := v2Tag.SetPaddingPolicy(opts.Padding); err != nil {
+			return err
+		}
+	}
+
+	if !f.Dirty() {
+		return nil
+	}
+
+	tag, err := f.tagBytes()
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(tag, f.saved) {
+		return nil
+	}
+
+	info, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// The audio comes after a v2 tag and before a v1 tag
+	audioStart, audioEnd := f.audioStart, info.Size()
+	switch f.Tagger.(type) {
+	case (*v1.Tag):
+		audioStart, audioEnd = 0, info.Size()-v1.TagSize
+	case (*v2.Tag):
+	default:
+		return errors.New("Save: unknown tag version")
+	}
+
+	if opts.InPlace && f.fits(tag, audioStart) {
+		err = f.writeInPlace(tag, audioEnd)
+	} else {
+		err = f.rewrite(tag, audioStart, audioEnd, info)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.PreserveModTime {
+		if err := os.Chtimes(f.file.Name(), time.Now(), info.ModTime()); err != nil {
+			return err
+		}
+	}
+
+	f.saved = tag
+	if _, ok := f.Tagger.(*v2.Tag); ok {
+		f.originalSize = f.Tagger.Size()
+		f.audioStart = int64(len(tag))
+	}
+
+	return nil
+}
+
+// Encodes the tag, failing on frames that cannot be written rather than
+// leaving them out
+func (f *File) tagBytes() ([]byte, error) {
+	if m, ok := f.Tagger.(encoding.BinaryMarshaler); ok {
+		return m.MarshalBinary()
+	}
+
+	return f.Tagger.Bytes(), nil
+}
+
+// Whether the tag fits in the space of the original tag
+func (f *File) fits(tag []byte, audioStart int64) bool {
+	if _, ok := f.Tagger.(*v1.Tag); ok {
+		return true
+	}
+
+	return int64(len(tag)) == audioStart
+}
+
+func (f *File) writeInPlace(tag []byte, audioEnd int64) error {
+	offset := int64(0)
+	if _, ok := f.Tagger.(*v1.Tag); ok {
+		offset = audioEnd
+	}
+
+	if _, err := f.file.WriteAt(tag, offset); err != nil {
+		return err
+	}
+
+	return f.file.Sync()
+}
+
+// Writes the tag and the audio to a temporary file and renames it over the
+// file
+func (f *File) rewrite(tag []byte, audioStart, audioEnd int64, info os.FileInfo) (err error) {
+	name := f.file.Name()
+	dir := filepath.Dir(name)
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(name)+".")
+	if err != nil {
+		return err
+	}
+
+	renamed := false
+	defer func() {
+		if !renamed {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	_, isV1 := f.Tagger.(*v1.Tag)
+
+	if !isV1 {
+		if _, err := tmp.Write(tag); err != nil {
+			return err
+		}
+	}
+
+	audio := io.NewSectionReader(f.file, audioStart, audioEnd-audioStart)
+	if _, err := io.Copy(tmp, audio); err != nil {
+		return err
+	}
+
+	if isV1 {
+		if _, err := tmp.Write(tag); err != nil {
+			return err
+		}
+	}
+
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	if err := chown(tmp, info); err != nil {
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Some platforms cannot replace a file that is open
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	renameErr := os.Rename(tmp.Name(), name)
+	if renameErr == nil {
+		renamed = true
+		syncDir(dir)
+	}
+
+	// Reopen whichever file is now in place, so that the file stays usable
+	if f.file, err = os.OpenFile(name, os.O_RDWR, 0); err != nil {
+		return err
+	}
+
+	return renameErr
+}
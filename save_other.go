@@ -0,0 +1,20 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build windows || plan9
+// +build windows plan9
+
+package id3
+
+import (
+	"os"
+)
+
+// Ownership is not kept on this platform
+func chown(file *os.File, info os.FileInfo) error {
+	return nil
+}
+
+// Directories cannot be synced on this platform
+func syncDir(name string) {}
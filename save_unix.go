@@ -0,0 +1,40 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package id3
+
+import (
+	"os"
+	"syscall"
+)
+
+// Gives a file the owner and group of the file it replaces
+// Only privileged users can give away files, so otherwise the file keeps the
+// owner of the process
+func chown(file *os.File, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	if err := file.Chown(int(stat.Uid), int(stat.Gid)); err != nil && !os.IsPermission(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Syncs a directory so that a rename in it survives a crash
+func syncDir(name string) {
+	dir, err := os.Open(name)
+	if err != nil {
+		return
+	}
+	defer dir.Close()
+
+	dir.Sync()
+}
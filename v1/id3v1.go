@@ -4,6 +4,8 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
 	v2 "github.com/mikkyang/id3-go/v2"
 	"io"
 	"os"
@@ -14,6 +16,9 @@ const (
 )
 
 var (
+	// ErrNoTag is returned when the data does not end with an ID3v1 tag
+	ErrNoTag = errors.New("id3v1: no tag")
+
 	Genres = []string{
 		"Blues", "Classic Rock", "Country", "Dance",
 		"Disco", "Funk", "Grunge", "Hip-Hop",
@@ -46,12 +51,36 @@ type Tag struct {
 }
 
 func ParseTag(readSeeker io.ReadSeeker) *Tag {
-	readSeeker.Seek(-TagSize, os.SEEK_END)
+	t, err := ReadTag(readSeeker)
+	if err != nil {
+		return nil
+	}
+
+	return t
+}
+
+// Reads a tag, returning ErrNoTag if the data does not end with a tag
+func ReadTag(readSeeker io.ReadSeeker) (*Tag, error) {
+	end, err := readSeeker.Seek(0, os.SEEK_END)
+	if err != nil {
+		return nil, err
+	}
+
+	if end < TagSize {
+		return nil, ErrNoTag
+	}
+
+	if _, err := readSeeker.Seek(-TagSize, os.SEEK_END); err != nil {
+		return nil, err
+	}
 
 	data := make([]byte, TagSize)
-	n, err := io.ReadFull(readSeeker, data)
-	if n < TagSize || err != nil || string(data[:3]) != "TAG" {
-		return nil
+	if n, err := io.ReadFull(readSeeker, data); err != nil {
+		return nil, fmt.Errorf("id3v1: read %d of %d bytes: %w", n, TagSize, err)
+	}
+
+	if string(data[:3]) != "TAG" {
+		return nil, ErrNoTag
 	}
 
 	return &Tag{
@@ -62,7 +91,7 @@ func ParseTag(readSeeker io.ReadSeeker) *Tag {
 		comment: string(data[97:127]),
 		genre:   data[127],
 		dirty:   false,
-	}
+	}, nil
 }
 
 func (t Tag) Dirty() bool {
@@ -0,0 +1,721 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ReceivedAs is how the audio of a commercial frame is delivered
+type ReceivedAs byte
+
+const (
+	ReceivedAsOther ReceivedAs = iota
+	ReceivedAsCDAlbum
+	ReceivedAsCompressedCD
+	ReceivedAsFile
+	ReceivedAsStream
+	ReceivedAsNoteSheets
+	ReceivedAsNoteSheetsBook
+	ReceivedAsOtherMedia
+	ReceivedAsMerchandise
+)
+
+const (
+	// Dates are written as YYYYMMDD
+	dateLayout = "20060102"
+	dateSize   = len(dateLayout)
+
+	// Separator of the prices of a commercial frame
+	priceSeparator = "/"
+)
+
+var (
+	receivedAsDescriptions = [...]string{
+		"Other",
+		"Standard CD album with other songs",
+		"Compressed audio on CD",
+		"File over the Internet",
+		"Stream over the Internet",
+		"As note sheets",
+		"As note sheets in a book with other sheets",
+		"Music on other media",
+		"Non-musical merchandise",
+	}
+
+	priceFormat = regexp.MustCompile(`^([A-Z]{3})(\d+(?:\.\d+)?)$`)
+
+	// Seller logos can only be PNG or JPEG images
+	logoMIMETypes = map[string]bool{
+		"image/png":  true,
+		"image/jpeg": true,
+	}
+)
+
+func (r ReceivedAs) String() string {
+	if int(r) < len(receivedAsDescriptions) {
+		return receivedAsDescriptions[r]
+	}
+
+	return "Unknown"
+}
+
+// Price is an amount of money in a currency
+type Price struct {
+	// ISO 4217 currency code, such as "USD"
+	Currency string
+
+	// Amount with "." as decimal separator, such as "1.99"
+	Amount string
+}
+
+func (p Price) String() string {
+	return p.Currency + p.Amount
+}
+
+func (p Price) validate() error {
+	if !priceFormat.MatchString(p.String()) {
+		return fmt.Errorf("price: invalid price %q", p.String())
+	}
+
+	return nil
+}
+
+func parsePrice(s string) (Price, error) {
+	m := priceFormat.FindStringSubmatch(s)
+	if m == nil {
+		return Price{}, fmt.Errorf("price: invalid price %q", s)
+	}
+
+	return Price{m[1], m[2]}, nil
+}
+
+func readDate(rd *encodedbytes.Reader) (time.Time, error) {
+	s, err := rd.ReadNumBytesString(dateSize)
+	if err != nil {
+		return time.Time{}, truncatedError(rd.Len(), dateSize)
+	}
+
+	// Dates that are not set are kept as the zero time
+	if s == strings.Repeat("0", dateSize) {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(dateLayout, s)
+}
+
+func dateString(t time.Time) string {
+	if t.IsZero() {
+		return strings.Repeat("0", dateSize)
+	}
+
+	return t.Format(dateLayout)
+}
+
+// Picks ISO-8859-1 for text when possible, and UTF-16 otherwise
+func textEncoding(texts ...string) byte {
+	for _, text := range texts {
+		if _, err := encodedbytes.Encoders[0].ConvertString(text); err != nil {
+			return encodedbytes.IndexForEncoding("UTF-16")
+		}
+	}
+
+	return 0
+}
+
+// Number of bytes of null terminated strings in an encoding
+func nullTermSize(encoding byte, texts ...string) (int, error) {
+	size := 0
+	for _, text := range texts {
+		encoded, err := encodedbytes.Encoders[encoding].ConvertString(text)
+		if err != nil {
+			return 0, err
+		}
+
+		size += len(encoded) + encodedbytes.EncodingNullLengthForIndex(encoding)
+	}
+
+	return size, nil
+}
+
+// CommercialFrame represents commercial frames, which describe offers to buy
+// the audio
+type CommercialFrame struct {
+	FrameHead
+	encoding     byte
+	prices       []Price
+	validUntil   time.Time
+	contactURL   string
+	receivedAs   ReceivedAs
+	seller       string
+	description  string
+	logoMIMEType string
+	logo         []byte
+}
+
+func NewCommercialFrame(ft FrameType, prices []Price, validUntil time.Time, contactURL string, receivedAs ReceivedAs, seller, description string) (*CommercialFrame, error) {
+	f := &CommercialFrame{
+		FrameHead:   FrameHead{FrameType: ft},
+		encoding:    textEncoding(seller, description),
+		prices:      append([]Price(nil), prices...),
+		validUntil:  validUntil,
+		contactURL:  contactURL,
+		receivedAs:  receivedAs,
+		seller:      seller,
+		description: description,
+	}
+
+	size, err := f.dataSize()
+	if err != nil {
+		return nil, err
+	}
+
+	f.size = uint32(size)
+	return f, nil
+}
+
+func ParseCommercialFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadCommercialFrame(head, data))
+}
+
+func ReadCommercialFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := &CommercialFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
+	}
+
+	prices, err := rd.ReadNullTermString(encodedbytes.NativeEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range strings.Split(prices, priceSeparator) {
+		price, err := parsePrice(s)
+		if err != nil {
+			return nil, err
+		}
+
+		f.prices = append(f.prices, price)
+	}
+
+	if f.validUntil, err = readDate(rd); err != nil {
+		return nil, err
+	}
+
+	if f.contactURL, err = rd.ReadNullTermString(encodedbytes.NativeEncoding); err != nil {
+		return nil, err
+	}
+
+	receivedAs, err := rd.ReadByte()
+	if err != nil {
+		return nil, truncatedError(len(data), len(data)+1)
+	}
+
+	f.receivedAs = ReceivedAs(receivedAs)
+
+	if f.seller, err = rd.ReadNullTermString(f.encoding); err != nil {
+		return nil, err
+	}
+
+	if f.description, err = rd.ReadNullTermString(f.encoding); err != nil {
+		return nil, err
+	}
+
+	// The seller logo is optional
+	if rd.Len() > 0 {
+		if f.logoMIMEType, err = rd.ReadNullTermString(encodedbytes.NativeEncoding); err != nil {
+			return nil, err
+		}
+
+		if f.logo, err = rd.ReadRest(); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// Number of bytes of the frame data, which also checks that the fields can
+// be written
+func (f CommercialFrame) dataSize() (int, error) {
+	if len(f.prices) == 0 {
+		return 0, errors.New("commercial: no prices")
+	}
+
+	for _, price := range f.prices {
+		if err := price.validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := validateURL(f.contactURL); err != nil {
+		return 0, err
+	}
+
+	if f.logo != nil && !logoMIMETypes[f.logoMIMEType] {
+		return 0, fmt.Errorf("commercial: seller logo cannot be %s", f.logoMIMEType)
+	}
+
+	texts, err := nullTermSize(f.encoding, f.seller, f.description)
+	if err != nil {
+		return 0, err
+	}
+
+	size := 1 + len(f.priceString()) + 1 + dateSize + len(f.contactURL) + 1 + 1 + texts
+	if f.logo != nil {
+		size += len(f.logoMIMEType) + 1 + len(f.logo)
+	}
+
+	return size, nil
+}
+
+// Applies a change to the frame if it can still be written
+func (f *CommercialFrame) change(update func(*CommercialFrame)) error {
+	changed := *f
+	update(&changed)
+
+	size, err := changed.dataSize()
+	if err != nil {
+		return err
+	}
+
+	update(f)
+	f.changeSize(size - int(f.size))
+	return nil
+}
+
+func (f CommercialFrame) priceString() string {
+	prices := make([]string, len(f.prices))
+	for i, price := range f.prices {
+		prices[i] = price.String()
+	}
+
+	return strings.Join(prices, priceSeparator)
+}
+
+func (f CommercialFrame) Encoding() string {
+	return encodedbytes.EncodingForIndex(f.encoding)
+}
+
+func (f *CommercialFrame) SetEncoding(encoding string) error {
+	i := encodedbytes.IndexForEncoding(encoding)
+	if encodedbytes.EncodingForIndex(i) != encoding {
+		return errors.New("encoding: invalid encoding")
+	}
+
+	return f.change(func(f *CommercialFrame) { f.encoding = i })
+}
+
+func (f CommercialFrame) Prices() []Price {
+	return append([]Price(nil), f.prices...)
+}
+
+func (f *CommercialFrame) SetPrices(prices []Price) error {
+	prices = append([]Price(nil), prices...)
+	return f.change(func(f *CommercialFrame) { f.prices = prices })
+}
+
+// Date until which the prices are valid
+func (f CommercialFrame) ValidUntil() time.Time {
+	return f.validUntil
+}
+
+func (f *CommercialFrame) SetValidUntil(validUntil time.Time) {
+	f.validUntil = validUntil
+	f.changeSize(0)
+}
+
+func (f CommercialFrame) ContactURL() string {
+	return f.contactURL
+}
+
+func (f *CommercialFrame) SetContactURL(contactURL string) error {
+	return f.change(func(f *CommercialFrame) { f.contactURL = contactURL })
+}
+
+func (f CommercialFrame) ReceivedAs() ReceivedAs {
+	return f.receivedAs
+}
+
+func (f *CommercialFrame) SetReceivedAs(receivedAs ReceivedAs) {
+	f.receivedAs = receivedAs
+	f.changeSize(0)
+}
+
+func (f CommercialFrame) Seller() string {
+	return f.seller
+}
+
+func (f *CommercialFrame) SetSeller(seller string) error {
+	return f.change(func(f *CommercialFrame) { f.seller = seller })
+}
+
+func (f CommercialFrame) Description() string {
+	return f.description
+}
+
+func (f *CommercialFrame) SetDescription(description string) error {
+	return f.change(func(f *CommercialFrame) { f.description = description })
+}
+
+// Seller logo and its MIME type, or nil if the frame has no logo
+func (f CommercialFrame) Logo() (string, []byte) {
+	return f.logoMIMEType, f.logo
+}
+
+// Sets the seller logo, which is a PNG or JPEG image, or removes it with nil
+// data
+// An empty MIME type is detected from the data
+func (f *CommercialFrame) SetLogo(mimeType string, data []byte) error {
+	if data == nil {
+		mimeType = ""
+	} else if mimeType == "" {
+		mimeType = SniffMIMEType(data)
+	}
+
+	return f.change(func(f *CommercialFrame) {
+		f.logoMIMEType = mimeType
+		f.logo = data
+	})
+}
+
+func (f CommercialFrame) String() string {
+	return fmt.Sprintf("%s until %s from %s: %s", f.priceString(), dateString(f.validUntil), f.seller, f.description)
+}
+
+func (f CommercialFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteByte(f.encoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.priceString(), encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteString(dateString(f.validUntil), encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.contactURL, encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteByte(byte(f.receivedAs)); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.seller, f.encoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.description, f.encoding); err != nil {
+		return bytes
+	}
+
+	if f.logo == nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.logoMIMEType, encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if n, err := wr.Write(f.logo); n < len(f.logo) || err != nil {
+		return bytes
+	}
+
+	return bytes
+}
+
+// OwnershipFrame represents ownership frames, which record the purchase of
+// the audio
+type OwnershipFrame struct {
+	FrameHead
+	encoding     byte
+	price        Price
+	purchaseDate time.Time
+	seller       string
+}
+
+func NewOwnershipFrame(ft FrameType, price Price, purchaseDate time.Time, seller string) (*OwnershipFrame, error) {
+	f := &OwnershipFrame{
+		FrameHead:    FrameHead{FrameType: ft},
+		encoding:     textEncoding(seller),
+		price:        price,
+		purchaseDate: purchaseDate,
+		seller:       seller,
+	}
+
+	size, err := f.dataSize()
+	if err != nil {
+		return nil, err
+	}
+
+	f.size = uint32(size)
+	return f, nil
+}
+
+func ParseOwnershipFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadOwnershipFrame(head, data))
+}
+
+func ReadOwnershipFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := &OwnershipFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
+	}
+
+	price, err := rd.ReadNullTermString(encodedbytes.NativeEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.price, err = parsePrice(price); err != nil {
+		return nil, err
+	}
+
+	if f.purchaseDate, err = readDate(rd); err != nil {
+		return nil, err
+	}
+
+	if f.seller, err = rd.ReadRestString(f.encoding); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f OwnershipFrame) dataSize() (int, error) {
+	if err := f.price.validate(); err != nil {
+		return 0, err
+	}
+
+	seller, err := encodedbytes.Encoders[f.encoding].ConvertString(f.seller)
+	if err != nil {
+		return 0, err
+	}
+
+	return 1 + len(f.price.String()) + 1 + dateSize + len(seller), nil
+}
+
+// Applies a change to the frame if it can still be written
+func (f *OwnershipFrame) change(update func(*OwnershipFrame)) error {
+	changed := *f
+	update(&changed)
+
+	size, err := changed.dataSize()
+	if err != nil {
+		return err
+	}
+
+	update(f)
+	f.changeSize(size - int(f.size))
+	return nil
+}
+
+func (f OwnershipFrame) Encoding() string {
+	return encodedbytes.EncodingForIndex(f.encoding)
+}
+
+func (f *OwnershipFrame) SetEncoding(encoding string) error {
+	i := encodedbytes.IndexForEncoding(encoding)
+	if encodedbytes.EncodingForIndex(i) != encoding {
+		return errors.New("encoding: invalid encoding")
+	}
+
+	return f.change(func(f *OwnershipFrame) { f.encoding = i })
+}
+
+// Price paid for the audio
+func (f OwnershipFrame) Price() Price {
+	return f.price
+}
+
+func (f *OwnershipFrame) SetPrice(price Price) error {
+	return f.change(func(f *OwnershipFrame) { f.price = price })
+}
+
+func (f OwnershipFrame) PurchaseDate() time.Time {
+	return f.purchaseDate
+}
+
+func (f *OwnershipFrame) SetPurchaseDate(purchaseDate time.Time) {
+	f.purchaseDate = purchaseDate
+	f.changeSize(0)
+}
+
+func (f OwnershipFrame) Seller() string {
+	return f.seller
+}
+
+func (f *OwnershipFrame) SetSeller(seller string) error {
+	return f.change(func(f *OwnershipFrame) { f.seller = seller })
+}
+
+func (f OwnershipFrame) String() string {
+	return fmt.Sprintf("%s on %s from %s", f.price, dateString(f.purchaseDate), f.seller)
+}
+
+func (f OwnershipFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteByte(f.encoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.price.String(), encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteString(dateString(f.purchaseDate), encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteString(f.seller, f.encoding); err != nil {
+		return bytes
+	}
+
+	return bytes
+}
+
+// TermsOfUseFrame represents terms of use frames
+type TermsOfUseFrame struct {
+	FrameHead
+	encoding byte
+	language string
+	text     string
+}
+
+func NewTermsOfUseFrame(ft FrameType, language, text string) (*TermsOfUseFrame, error) {
+	if len(language) != 3 {
+		return nil, errors.New("language: invalid language string")
+	}
+
+	encoding := textEncoding(text)
+	encoded, err := encodedbytes.Encoders[encoding].ConvertString(text)
+	if err != nil {
+		return nil, err
+	}
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(1 + 3 + len(encoded)),
+	}
+
+	return &TermsOfUseFrame{head, encoding, language, text}, nil
+}
+
+func ParseTermsOfUseFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadTermsOfUseFrame(head, data))
+}
+
+func ReadTermsOfUseFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := &TermsOfUseFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
+	}
+
+	if f.language, err = rd.ReadNumBytesString(3); err != nil {
+		return nil, truncatedError(len(data), 1+3)
+	}
+
+	if f.text, err = rd.ReadRestString(f.encoding); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f TermsOfUseFrame) Encoding() string {
+	return encodedbytes.EncodingForIndex(f.encoding)
+}
+
+func (f *TermsOfUseFrame) SetEncoding(encoding string) error {
+	i := encodedbytes.IndexForEncoding(encoding)
+	if encodedbytes.EncodingForIndex(i) != encoding {
+		return errors.New("encoding: invalid encoding")
+	}
+
+	diff, err := encodedbytes.EncodedDiff(i, f.text, f.encoding, f.text)
+	if err != nil {
+		return err
+	}
+
+	f.changeSize(diff)
+	f.encoding = i
+	return nil
+}
+
+func (f TermsOfUseFrame) Language() string {
+	return f.language
+}
+
+func (f *TermsOfUseFrame) SetLanguage(language string) error {
+	if len(language) != 3 {
+		return errors.New("language: invalid language string")
+	}
+
+	f.language = language
+	f.changeSize(0)
+	return nil
+}
+
+func (f TermsOfUseFrame) Text() string {
+	return f.text
+}
+
+func (f *TermsOfUseFrame) SetText(text string) error {
+	diff, err := encodedbytes.EncodedDiff(f.encoding, text, f.encoding, f.text)
+	if err != nil {
+		return err
+	}
+
+	f.changeSize(diff)
+	f.text = text
+	return nil
+}
+
+func (f TermsOfUseFrame) String() string {
+	return fmt.Sprintf("%s\t%s", f.language, f.text)
+}
+
+func (f TermsOfUseFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteByte(f.encoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteString(f.language, encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteString(f.text, f.encoding); err != nil {
+		return bytes
+	}
+
+	return bytes
+}
@@ -0,0 +1,97 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCommercialFrame(t *testing.T) {
+	data := []byte("\x00USD1.99/EUR1.50\x0020141231http://example.com\x00\x03Shop\x00Single\x00image/png\x00\x89PNG")
+
+	frame, err := ReadCommercialFrame(FrameHead{FrameType: V23FrameTypeMap["COMR"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*CommercialFrame)
+	prices := []Price{{"USD", "1.99"}, {"EUR", "1.50"}}
+	if !reflect.DeepEqual(f.Prices(), prices) {
+		t.Errorf("ReadCommercialFrame incorrect prices %v", f.Prices())
+	}
+
+	if f.ValidUntil() != time.Date(2014, 12, 31, 0, 0, 0, 0, time.UTC) || f.ContactURL() != "http://example.com" {
+		t.Errorf("ReadCommercialFrame incorrect frame, %v", f)
+	}
+
+	if f.ReceivedAs() != ReceivedAsFile || f.Seller() != "Shop" || f.Description() != "Single" {
+		t.Errorf("ReadCommercialFrame incorrect frame, %v", f)
+	}
+
+	if mimeType, logo := f.Logo(); mimeType != "image/png" || !bytes.Equal(logo, []byte("\x89PNG")) {
+		t.Errorf("ReadCommercialFrame incorrect logo %s %v", mimeType, logo)
+	}
+
+	if b := f.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	if err := f.SetLogo("image/gif", []byte("GIF89a")); err == nil {
+		t.Errorf("SetLogo succeeded with a GIF logo")
+	}
+
+	if err := f.SetPrices([]Price{{"usd", "1"}}); err == nil {
+		t.Errorf("SetPrices succeeded with an invalid currency")
+	}
+
+	if err := f.SetLogo("", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if b := f.Bytes(); len(b) != int(f.Size()) || !bytes.HasSuffix(b, []byte("Single\x00")) {
+		t.Errorf("SetLogo without a logo produces %v", b)
+	}
+}
+
+func TestOwnershipAndTermsOfUseFrames(t *testing.T) {
+	purchased := time.Date(2014, 3, 9, 0, 0, 0, 0, time.UTC)
+	owne, err := NewOwnershipFrame(V24FrameTypeMap["OWNE"], Price{"GBP", "0.79"}, purchased, "Boutique Café")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := NewTermsOfUseFrame(V24FrameTypeMap["USER"], "eng", "Personal use only")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag := NewTag(4)
+	tag.AddFrames(owne, user)
+
+	parsed, err := ReadTag(bytes.NewReader(tag.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, ok := parsed.Frame("OWNE").(*OwnershipFrame)
+	if !ok || o.Price() != (Price{"GBP", "0.79"}) || o.PurchaseDate() != purchased || o.Seller() != "Boutique Café" {
+		t.Errorf("incorrect ownership frame %v", parsed.Frame("OWNE"))
+	}
+
+	u, ok := parsed.Frame("USER").(*TermsOfUseFrame)
+	if !ok || u.Language() != "eng" || u.Text() != "Personal use only" {
+		t.Errorf("incorrect terms of use frame %v", parsed.Frame("USER"))
+	}
+
+	if _, err := NewOwnershipFrame(V24FrameTypeMap["OWNE"], Price{"GBP", "0,79"}, purchased, ""); err == nil {
+		t.Errorf("NewOwnershipFrame succeeded with an invalid price")
+	}
+
+	if _, err := NewTermsOfUseFrame(V24FrameTypeMap["USER"], "en", ""); err == nil {
+		t.Errorf("NewTermsOfUseFrame succeeded with an invalid language")
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+)
+
+var (
+	// V22 frame IDs for the ID3v2.3 frame IDs they were replaced by
+	v22TypeMap = make(map[string]string)
+)
+
+func init() {
+	for v22, v23 := range V23DeprecatedTypeMap {
+		v22TypeMap[v23] = v22
+	}
+}
+
+// Converts the tag and its frames to another ID3v2 version
+// Frames that have no equivalent in the target version are dropped, as are
+// encrypted frames that could not be decrypted and, since ID3v2.2 has no
+// encryption, every encrypted frame when converting to ID3v2.2
+// ID3v2.3 recording dates, and dates and times without a year, have no
+// ID3v2.4 frame, so they are kept as user defined text frames described by
+// their ID3v2.3 frame ID
+func (t *Tag) ConvertTo(version byte) error {
+	if version < 2 || version > 4 {
+		return fmt.Errorf("convert: unsupported version 2.%d", version)
+	}
+
+	if t.version < 2 || t.version > 4 {
+		return fmt.Errorf("convert: unsupported version 2.%d", t.version)
+	}
+
+	if version == t.version {
+		return nil
+	}
+
+	frames := t.AllFrames()
+	for from := t.version; from != version; {
+		to := from + 1
+		if version < from {
+			to = from - 1
+		}
+
+		frames = convertFrames(frames, from, to)
+		from = to
+	}
+
+	t.frames = frames
+	t.setVersion(version)
+	t.revision = 0
+	t.flags &= 1 << 7
+	t.compression = false
+	t.extendedHeader = false
+	t.experimental = false
+	if version < 4 {
+		t.footer = false
+	}
+
+	size := 0
+
+	// The extended header is kept, along with the fields the target version
+	// has, unless the target version is ID3v2.2
+	if t.extHeader != nil && version < 3 {
+		t.extHeader.owner = nil
+		t.extHeader = nil
+	}
+	if t.extHeader != nil {
+		t.extendedHeader = true
+		t.flags |= 1 << 6
+		size += t.extHeader.size(version)
+	}
+	for _, frame := range frames {
+		frame.setOwner(t)
+
+		size += t.frameHeaderSize + int(frame.Size())
+	}
+
+	if t.footer {
+		t.padding = 0
+	}
+	t.size = uint32(size) + uint32(t.padding)
+	t.modified()
+
+	return nil
+}
+
+// Converts frames between adjacent versions
+func convertFrames(frames []Framer, from, to byte) []Framer {
+	var typeMap map[string]FrameType
+	switch to {
+	case 2:
+		typeMap = V22FrameTypeMap
+	case 3:
+		typeMap = V23FrameTypeMap
+	case 4:
+		typeMap = V24FrameTypeMap
+	}
+
+	converted := make([]Framer, 0, len(frames))
+	dates := make(map[string]string)
+	var people []InvolvedPerson
+
+	for _, f := range frames {
+		// The contents of transformed frames are unknown
+		if f.FormatFlags() != 0 {
+			continue
+		}
+
+		if _, encrypted := f.EncryptionMethod(); encrypted && to == 2 {
+			continue
+		}
+
+		// ID3v2.2 has the same date and time frames as ID3v2.3, which are
+		// converted like any other frame
+		if to > 2 && isDateFrameId(f.Id(), from) {
+			if textFrame, ok := f.(TextFramer); ok {
+				dates[f.Id()] = textFrame.Text()
+			}
+			continue
+		}
+
+		// ID3v2.3 has a single involved people list for everyone
+		if from == 4 && to == 3 && (f.Id() == "TIPL" || f.Id() == "TMCL") {
+			if peopleFrame, ok := f.(*InvolvedPeopleFrame); ok {
+				people = append(people, peopleFrame.People()...)
+			}
+			continue
+		}
+
+		id := convertFrameId(f.Id(), from, to)
+
+		ft, ok := typeMap[id]
+		if !ok {
+			continue
+		}
+
+		data := f.Bytes()
+		switch {
+		case from == 2 && id == "APIC":
+			data = pictureToImageData(data)
+		case to == 2 && id == "PIC":
+			data = imageToPictureData(data)
+		}
+
+		if data == nil {
+			continue
+		}
+
+		head := FrameHead{
+			FrameType:   ft,
+			statusFlags: convertStatusFlags(f.StatusFlags(), from, to),
+			size:        uint32(len(data)),
+		}
+
+		// ID3v2.2 frames have no group identifier
+		if to > 2 {
+			head.groupId, head.grouped = f.GroupId()
+			head.method, head.encrypted = f.EncryptionMethod()
+		}
+
+		frame, _ := constructFrame(head, data, false)
+		if to < 4 {
+			downgradeEncoding(frame)
+		}
+
+		converted = append(converted, frame)
+	}
+
+	if len(people) > 0 {
+		converted = append(converted, NewInvolvedPeopleFrame(typeMap["IPLS"], people))
+	}
+
+	return append(converted, convertDates(dates, from, to, typeMap)...)
+}
+
+// Frame ID of a frame in the adjacent version
+func convertFrameId(id string, from, to byte) string {
+	switch {
+	case from == 2:
+		return V23DeprecatedTypeMap[id]
+	case to == 2:
+		return v22TypeMap[id]
+	case to == 4:
+		if renamed, ok := V24DeprecatedTypeMap[id]; ok {
+			return renamed
+		}
+	case from == 4:
+		for v23, v24 := range V24DeprecatedTypeMap {
+			if v24 == id {
+				return v23
+			}
+		}
+	}
+
+	return id
+}
+
+// Whether the frame is one of the date and time frames that are merged or
+// split when converting between ID3v2.3 and ID3v2.4
+func isDateFrameId(id string, version byte) bool {
+	switch version {
+	case 3:
+		return id == "TYER" || id == "TDAT" || id == "TIME" || id == "TRDA" || id == "TORY"
+	case 4:
+		return id == "TDRC" || id == "TDOR"
+	}
+
+	return false
+}
+
+// Converts the date and time frames between ID3v2.3 and ID3v2.4
+// ID3v2.3 has a year frame, a DDMM date frame and a HHMM time frame, which
+// ID3v2.4 combines into a yyyy-MM-ddTHH:mm recording time frame
+// The original release year becomes an original release time and back
+// ID3v2.3 dates that cannot be converted become user defined text frames
+func convertDates(dates map[string]string, from, to byte, typeMap map[string]FrameType) []Framer {
+	var frames []Framer
+
+	switch {
+	case from == 3 && to == 4:
+		merged := make(map[string]bool)
+
+		if year := dates["TORY"]; len(year) == 4 {
+			frames = append(frames, NewTextFrame(typeMap["TDOR"], year))
+			merged["TORY"] = true
+		}
+
+		if year := dates["TYER"]; len(year) == 4 {
+			timestamp := year
+			merged["TYER"] = true
+
+			if date := dates["TDAT"]; len(date) == 4 {
+				timestamp += "-" + date[2:4] + "-" + date[0:2]
+				merged["TDAT"] = true
+
+				if time := dates["TIME"]; len(time) == 4 {
+					timestamp += "T" + time[0:2] + ":" + time[2:4]
+					merged["TIME"] = true
+				}
+			}
+
+			frames = append(frames, NewTextFrame(typeMap["TDRC"], timestamp))
+		}
+
+		// ID3v2.4 has no recording dates frame, and dates without a year
+		// cannot be part of a timestamp, so they are kept as user defined
+		// text described by their frame ID
+		for _, id := range []string{"TYER", "TDAT", "TIME", "TRDA", "TORY"} {
+			if text, ok := dates[id]; ok && !merged[id] {
+				frames = append(frames, newUserTextFrame(typeMap["TXXX"], id, text))
+			}
+		}
+	case from == 4 && to == 3:
+		if timestamp := dates["TDOR"]; len(timestamp) >= 4 {
+			frames = append(frames, NewTextFrame(typeMap["TORY"], timestamp[0:4]))
+		}
+
+		timestamp := dates["TDRC"]
+		if len(timestamp) >= 4 {
+			frames = append(frames, NewTextFrame(typeMap["TYER"], timestamp[0:4]))
+		}
+
+		if len(timestamp) >= 10 {
+			date := timestamp[8:10] + timestamp[5:7]
+			frames = append(frames, NewTextFrame(typeMap["TDAT"], date))
+		}
+
+		if len(timestamp) >= 16 {
+			time := timestamp[11:13] + timestamp[14:16]
+			frames = append(frames, NewTextFrame(typeMap["TIME"], time))
+		}
+	}
+
+	return frames
+}
+
+// Creates a user defined text frame, with UTF-16 text if it cannot be
+// ISO-8859-1
+func newUserTextFrame(ft FrameType, description, text string) Framer {
+	f := NewDescTextFrame(ft, description, "")
+	f.SetEncoding(encodedbytes.EncodingForIndex(textEncoding(text)))
+	f.SetText(text)
+
+	return f
+}
+
+// Status flags are the same in ID3v2.3 and ID3v2.4, but shifted by one bit
+// ID3v2.2 frames have no flags
+func convertStatusFlags(flags byte, from, to byte) byte {
+	switch {
+	case from == 3 && to == 4:
+		return (flags >> 1) & 0x70
+	case from == 4 && to == 3:
+		return (flags << 1) & 0xe0
+	}
+
+	return 0
+}
+
+// UTF-8 and UTF-16 without BOM are only allowed from ID3v2.4
+func downgradeEncoding(f Framer) {
+	encoder, ok := f.(interface {
+		Encoding() string
+		SetEncoding(string) error
+	})
+	if !ok {
+		return
+	}
+
+	if i := encodedbytes.IndexForEncoding(encoder.Encoding()); i > 1 {
+		encoder.SetEncoding("UTF-16")
+	}
+}
+
+// Converts the data of an ID3v2.2 picture frame, which has a three character
+// image format, to the data of a picture frame, which has a MIME type
+func pictureToImageData(data []byte) []byte {
+	if len(data) < 1+pictureFormatSize {
+		return nil
+	}
+
+	mimeType := pictureFormatMIMEType(string(data[1 : 1+pictureFormatSize]))
+
+	converted := make([]byte, 0, len(data)+len(mimeType))
+	converted = append(converted, data[0])
+	converted = append(converted, mimeType...)
+	converted = append(converted, 0)
+
+	return append(converted, data[1+pictureFormatSize:]...)
+}
+
+// Converts the data of a picture frame to the data of an ID3v2.2 picture
+// frame
+func imageToPictureData(data []byte) []byte {
+	if len(data) < 1 {
+		return nil
+	}
+
+	rd := encodedbytes.NewReader(data[1:])
+	mimeType, err := rd.ReadNullTermString(encodedbytes.NativeEncoding)
+	if err != nil {
+		return nil
+	}
+
+	rest, _ := rd.ReadRest()
+
+	converted := make([]byte, 0, 1+pictureFormatSize+len(rest))
+	converted = append(converted, data[0])
+	converted = append(converted, pictureFormat(mimeType)...)
+
+	return append(converted, rest...)
+}
@@ -0,0 +1,134 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestConvertV22ToV23(t *testing.T) {
+	tag := NewTag(2)
+	tag.SetTitle("Nice Life")
+	tag.SetArtist("Paloalto")
+	tag.SetYear("2013")
+	tag.AddFrames(NewDescTextFrame(V22FrameTypeMap["TXX"], "Label", "Hi-Lite"))
+
+	pictureData := []byte{0, 'J', 'P', 'G', 3, 'C', 'o', 'v', 'e', 'r', 0, 0xff, 0xd8}
+	tag.AddFrames(NewDataFrame(V22FrameTypeMap["PIC"], pictureData))
+
+	if err := tag.ConvertTo(3); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := ParseTag(bytes.NewReader(tag.Bytes()))
+	if parsed == nil {
+		t.Fatal("ParseTag could not parse converted tag")
+	}
+
+	if v := parsed.Version(); v != "2.3.0" {
+		t.Errorf("converted tag has version %s", v)
+	}
+
+	if s := parsed.Title(); s != "Nice Life" {
+		t.Errorf("converted tag has title %q", s)
+	}
+
+	if s := parsed.Artist(); s != "Paloalto" {
+		t.Errorf("converted tag has artist %q", s)
+	}
+
+	if s := parsed.Year(); s != "2013" {
+		t.Errorf("converted tag has year %q", s)
+	}
+
+	if f, ok := parsed.Frame("TXXX").(*DescTextFrame); !ok || f.Description() != "Label" || f.Text() != "Hi-Lite" {
+		t.Errorf("converted tag has user text frame %v", parsed.Frame("TXXX"))
+	}
+
+	image, ok := parsed.Frame("APIC").(*ImageFrame)
+	if !ok {
+		t.Fatalf("converted tag has picture frame %v", parsed.Frame("APIC"))
+	}
+
+	if m := image.MIMEType(); m != "image/jpeg" {
+		t.Errorf("converted picture has MIME type %q", m)
+	}
+
+	if !bytes.Equal(image.Data(), []byte{0xff, 0xd8}) {
+		t.Errorf("converted picture has data %v", image.Data())
+	}
+
+	if err := parsed.ConvertTo(2); err != nil {
+		t.Fatal(err)
+	}
+
+	picture, ok := parsed.Frame("PIC").(*PictureFrame)
+	if !ok || !bytes.Equal(picture.Bytes(), pictureData) {
+		t.Errorf("picture converted back to %v", parsed.Frame("PIC"))
+	}
+
+	if s := parsed.Year(); s != "2013" {
+		t.Errorf("tag converted back has year %q", s)
+	}
+}
+
+func TestConvertV23ToV24Dates(t *testing.T) {
+	tag := NewTag(3)
+	tag.SetYear("2013")
+	tag.AddFrames(NewTextFrame(V23FrameTypeMap["TDAT"], "2403"))
+	tag.AddFrames(NewTextFrame(V23FrameTypeMap["TIME"], "1830"))
+	tag.AddFrames(NewTextFrame(V23FrameTypeMap["TORY"], "2012"))
+
+	if err := tag.ConvertTo(4); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := tag.Year(); s != "2013-03-24T18:30" {
+		t.Errorf("converted tag has recording time %q", s)
+	}
+
+	if s := tag.textFrameText(V24FrameTypeMap["TDOR"]); s != "2012" {
+		t.Errorf("converted tag has original release time %q", s)
+	}
+
+	if err := tag.ConvertTo(3); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, text := range map[string]string{"TYER": "2013", "TDAT": "2403", "TIME": "1830", "TORY": "2012"} {
+		if s := tag.textFrameText(V23FrameTypeMap[id]); s != text {
+			t.Errorf("converted tag has %s %q, expected %q", id, s, text)
+		}
+	}
+	if err := tag.ConvertTo(2); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, text := range map[string]string{"TYE": "2013", "TDA": "2403", "TIM": "1830", "TOR": "2012"} {
+		if s := tag.textFrameText(V22FrameTypeMap[id]); s != text {
+			t.Errorf("converted tag has %s %q, expected %q", id, s, text)
+		}
+	}
+}
+
+func TestConvertV23ToV24UnmergedDates(t *testing.T) {
+	tag := NewTag(3)
+	tag.AddFrames(NewTextFrame(V23FrameTypeMap["TDAT"], "2403"))
+	tag.AddFrames(NewTextFrame(V23FrameTypeMap["TRDA"], "March 24-25, 2013"))
+
+	if err := tag.ConvertTo(4); err != nil {
+		t.Fatal(err)
+	}
+
+	if f := tag.Frame("TDRC"); f != nil {
+		t.Errorf("converted tag has recording time %q without a year", f)
+	}
+
+	for description, text := range map[string]string{"TDAT": "2403", "TRDA": "March 24-25, 2013"} {
+		if f, ok := tag.userTextFrame(description); !ok || f.Text() != text {
+			t.Errorf("converted tag has no %s user defined text %q", description, text)
+		}
+	}
+}
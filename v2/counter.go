@@ -0,0 +1,302 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"math/big"
+)
+
+// Counters are at least 32 bits and grow by a byte when they overflow
+const minCounterSize = 4
+
+func counterBytes(n *big.Int) []byte {
+	b := n.Bytes()
+	if len(b) < minCounterSize {
+		b = append(make([]byte, minCounterSize-len(b)), b...)
+	}
+
+	return b
+}
+
+func counterSize(n *big.Int) int {
+	if size := (n.BitLen() + 7) / 8; size > minCounterSize {
+		return size
+	}
+
+	return minCounterSize
+}
+
+// PlayCounterFrame represents frames that count the number of times a file
+// has been played
+type PlayCounterFrame struct {
+	FrameHead
+	counter *big.Int
+}
+
+func NewPlayCounterFrame(ft FrameType, count uint64) *PlayCounterFrame {
+	counter := new(big.Int).SetUint64(count)
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(counterSize(counter)),
+	}
+
+	return &PlayCounterFrame{head, counter}
+}
+
+func ParsePlayCounterFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadPlayCounterFrame(head, data))
+}
+
+func ReadPlayCounterFrame(head FrameHead, data []byte) (Framer, error) {
+	if len(data) < minCounterSize {
+		return nil, truncatedError(len(data), minCounterSize)
+	}
+
+	// Counters are written with as few bytes as they need
+	f := &PlayCounterFrame{head, new(big.Int).SetBytes(data)}
+	f.size = uint32(counterSize(f.counter))
+	return f, nil
+}
+
+// Number of times the file has been played
+func (f PlayCounterFrame) Counter() *big.Int {
+	return new(big.Int).Set(f.counter)
+}
+
+func (f *PlayCounterFrame) SetCounter(counter *big.Int) {
+	f.changeSize(counterSize(counter) - counterSize(f.counter))
+	f.counter = new(big.Int).Set(counter)
+}
+
+// Adds one to the counter
+func (f *PlayCounterFrame) Increment() {
+	f.SetCounter(new(big.Int).Add(f.counter, big.NewInt(1)))
+}
+
+func (f PlayCounterFrame) String() string {
+	return f.counter.String()
+}
+
+func (f PlayCounterFrame) Bytes() []byte {
+	return counterBytes(f.counter)
+}
+
+// PopularimeterFrame represents frames that rate a file for a user, along
+// with an optional count of the times the user has played it
+type PopularimeterFrame struct {
+	FrameHead
+	email   string
+	rating  byte
+	counter *big.Int
+}
+
+// Creates a popularimeter frame without a counter
+// Ratings go from 1, the worst, to 255, the best, and 0 is unknown
+func NewPopularimeterFrame(ft FrameType, email string, rating byte) (*PopularimeterFrame, error) {
+	encoded, err := encodeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(len(encoded) + 1 + 1),
+	}
+
+	return &PopularimeterFrame{
+		FrameHead: head,
+		email:     email,
+		rating:    rating,
+	}, nil
+}
+
+// The email address is always ISO-8859-1
+func encodeEmail(email string) (string, error) {
+	encoded, err := encodedbytes.Encoders[0].ConvertString(email)
+	if err != nil {
+		return "", fmt.Errorf("popularimeter: email %q: %w", email, err)
+	}
+
+	return encoded, nil
+}
+
+func ParsePopularimeterFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadPopularimeterFrame(head, data))
+}
+
+func ReadPopularimeterFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := &PopularimeterFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	// The email address is always ISO-8859-1
+	if f.email, err = rd.ReadNullTermString(0); err != nil {
+		return nil, err
+	}
+
+	if f.rating, err = rd.ReadByte(); err != nil {
+		return nil, err
+	}
+
+	counter, err := rd.ReadRest()
+	if err != nil {
+		return nil, err
+	}
+
+	// The counter can be left out
+	if len(counter) > 0 {
+		if len(counter) < minCounterSize {
+			return nil, truncatedError(len(counter), minCounterSize)
+		}
+
+		f.counter = new(big.Int).SetBytes(counter)
+	}
+
+	// Counters are written with as few bytes as they need
+	size := len(data) - len(counter)
+	if f.counter != nil {
+		size += counterSize(f.counter)
+	}
+
+	f.size = uint32(size)
+	return f, nil
+}
+
+// Email address of the user the rating belongs to
+func (f PopularimeterFrame) Email() string {
+	return f.email
+}
+
+func (f *PopularimeterFrame) SetEmail(email string) error {
+	diff, err := encodedbytes.EncodedDiff(0, email, 0, f.email)
+	if err != nil {
+		return fmt.Errorf("popularimeter: email %q: %w", email, err)
+	}
+
+	f.changeSize(diff)
+	f.email = email
+	return nil
+}
+
+func (f PopularimeterFrame) Rating() byte {
+	return f.rating
+}
+
+func (f *PopularimeterFrame) SetRating(rating byte) {
+	f.rating = rating
+	f.changeSize(0)
+}
+
+// Number of times the user has played the file, or nil if the frame has no
+// counter
+func (f PopularimeterFrame) Counter() *big.Int {
+	if f.counter == nil {
+		return nil
+	}
+
+	return new(big.Int).Set(f.counter)
+}
+
+// Sets the counter, or removes it with nil
+func (f *PopularimeterFrame) SetCounter(counter *big.Int) {
+	diff := 0
+	if f.counter != nil {
+		diff -= counterSize(f.counter)
+	}
+
+	if counter != nil {
+		diff += counterSize(counter)
+		counter = new(big.Int).Set(counter)
+	}
+
+	f.changeSize(diff)
+	f.counter = counter
+}
+
+// Adds one to the counter, adding a counter if the frame has none
+func (f *PopularimeterFrame) Increment() {
+	counter := big.NewInt(1)
+	if f.counter != nil {
+		counter.Add(counter, f.counter)
+	}
+
+	f.SetCounter(counter)
+}
+
+func (f PopularimeterFrame) String() string {
+	if f.counter == nil {
+		return fmt.Sprintf("%s: %d", f.email, f.rating)
+	}
+
+	return fmt.Sprintf("%s: %d (%s)", f.email, f.rating, f.counter)
+}
+
+func (f PopularimeterFrame) Bytes() []byte {
+	data := make([]byte, 0, f.Size())
+
+	email, err := encodeEmail(f.email)
+	if err != nil {
+		return data
+	}
+
+	data = append(data, email...)
+	data = append(data, 0, f.rating)
+	if f.counter != nil {
+		data = append(data, counterBytes(f.counter)...)
+	}
+
+	return data
+}
+
+// Rating of the file by a user, which is 0 if the user has not rated it
+func (t Tag) Rating(email string) byte {
+	if f := t.popularimeter(email); f != nil {
+		return f.Rating()
+	}
+
+	return 0
+}
+
+// Sets the rating of the file by a user, adding a popularimeter frame for
+// the user if the tag has none
+// The email address has to be ISO-8859-1
+func (t *Tag) SetRating(email string, rating byte) error {
+	if f := t.popularimeter(email); f != nil {
+		f.SetRating(rating)
+		return nil
+	}
+
+	f, err := NewPopularimeterFrame(t.commonMap["Popularimeter"], email, rating)
+	if err != nil {
+		return err
+	}
+
+	t.AddFrames(f)
+	return nil
+}
+
+// Adds one to the play counter, adding a play counter frame if the tag has
+// none
+func (t *Tag) IncrementPlayCount() {
+	ft := t.commonMap["PlayCounter"]
+	if f, ok := t.Frame(ft.Id()).(*PlayCounterFrame); ok {
+		f.Increment()
+		return
+	}
+
+	t.AddFrames(NewPlayCounterFrame(ft, 1))
+}
+
+func (t Tag) popularimeter(email string) *PopularimeterFrame {
+	for _, frame := range t.Frames(t.commonMap["Popularimeter"].Id()) {
+		if f, ok := frame.(*PopularimeterFrame); ok && f.Email() == email {
+			return f
+		}
+	}
+
+	return nil
+}
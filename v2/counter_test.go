@@ -0,0 +1,111 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestPopularimeterFrame(t *testing.T) {
+	data := []byte{'m', '@', 'y', 0, 196, 0, 0, 1, 0}
+	frame, err := ReadPopularimeterFrame(FrameHead{FrameType: V23FrameTypeMap["POPM"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*PopularimeterFrame)
+	if f.Email() != "m@y" || f.Rating() != 196 || f.Counter().Int64() != 256 {
+		t.Errorf("ReadPopularimeterFrame incorrect frame, %v", f)
+	}
+
+	if b := f.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	// Counters grow past 32 bits
+	f.SetCounter(new(big.Int).Lsh(big.NewInt(1), 40))
+	if b := f.Bytes(); len(b) != int(f.Size()) || len(b) != 4+1+6 {
+		t.Errorf("large counter has %d bytes and size %d", len(b), f.Size())
+	}
+
+	f.SetCounter(nil)
+	if b := f.Bytes(); len(b) != int(f.Size()) || len(b) != 4+1 {
+		t.Errorf("frame without counter has %d bytes and size %d", len(b), f.Size())
+	}
+}
+
+func TestTagRatingAndPlayCount(t *testing.T) {
+	tag := NewTag(3)
+	tag.SetRating("m@y", 128)
+	tag.SetRating("p@a", 255)
+	tag.SetRating("m@y", 64)
+	tag.IncrementPlayCount()
+	tag.IncrementPlayCount()
+
+	parsed, err := ReadTag(bytes.NewReader(tag.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(parsed.Frames("POPM")); n != 2 {
+		t.Errorf("SetRating added %d popularimeter frames", n)
+	}
+
+	if r := parsed.Rating("m@y"); r != 64 {
+		t.Errorf("incorrect rating %d", r)
+	}
+
+	if r := parsed.Rating("unknown"); r != 0 {
+		t.Errorf("incorrect rating %d for unknown user", r)
+	}
+
+	counter, ok := parsed.Frame("PCNT").(*PlayCounterFrame)
+	if !ok || counter.Counter().Int64() != 2 {
+		t.Errorf("incorrect play counter %v", parsed.Frame("PCNT"))
+	}
+}
+
+func TestPopularimeterEmailEncoding(t *testing.T) {
+	f, err := NewPopularimeterFrame(V23FrameTypeMap["POPM"], "josé@example.com", 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := f.Bytes()
+	if len(data) != int(f.Size()) || !bytes.HasPrefix(data, []byte("jos\xe9@example.com\x00")) {
+		t.Errorf("Bytes produces %v", data)
+	}
+
+	parsed, err := ReadPopularimeterFrame(FrameHead{FrameType: V23FrameTypeMap["POPM"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b := parsed.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes of parsed frame differ, expected %v not %v", data, b)
+	}
+
+	if _, err := NewPopularimeterFrame(V23FrameTypeMap["POPM"], "✓@example.com", 128); err == nil {
+		t.Errorf("NewPopularimeterFrame succeeded with an email that is not ISO-8859-1")
+	}
+
+	if err := f.SetEmail("✓@example.com"); err == nil || f.Email() != "josé@example.com" {
+		t.Errorf("SetEmail succeeded with an email that is not ISO-8859-1")
+	}
+}
+
+func TestPlayCounterFrameWideCounter(t *testing.T) {
+	// Counters that are wider than they need to be are written minimally
+	data := []byte{0, 0, 0, 0, 5}
+	frame, err := ReadPlayCounterFrame(FrameHead{FrameType: V23FrameTypeMap["PCNT"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b := frame.Bytes(); int(frame.Size()) != len(b) || !bytes.Equal(b, []byte{0, 0, 0, 5}) {
+		t.Errorf("Bytes produces %v with size %d", b, frame.Size())
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// Size of the header of a CD table of contents, which has the data
+	// length and the first and last track numbers
+	tocHeaderSize = 4
+
+	// Size of a track descriptor of a CD table of contents
+	tocTrackSize = 8
+
+	// Track number of the lead-out area
+	tocLeadOut = 0xaa
+
+	// ADR/control of a lead-out area with position information
+	tocLeadOutControl = 0x10
+
+	// Unique file identifiers are at most 64 bytes
+	maxUniqueIDSize = 64
+)
+
+// TOCTrack is a track in a CD table of contents
+type TOCTrack struct {
+	Number byte
+
+	// ADR and control fields, which describe the track type
+	Control byte
+
+	// Start of the track as a logical block address
+	Offset uint32
+}
+
+// TableOfContents is the table of contents of an audio CD
+type TableOfContents struct {
+	FirstTrack byte
+	LastTrack  byte
+	Tracks     []TOCTrack
+
+	// Start of the lead-out area as a logical block address
+	LeadOut uint32
+}
+
+// Number of tracks on the CD
+func (toc TableOfContents) TrackCount() int {
+	return len(toc.Tracks)
+}
+
+// Binary table of contents as returned by the READ TOC command of a CD drive
+func (toc TableOfContents) Bytes() []byte {
+	size := tocHeaderSize + (len(toc.Tracks)+1)*tocTrackSize
+	b := make([]byte, size)
+
+	// The data length does not include itself
+	binary.BigEndian.PutUint16(b, uint16(size-2))
+	b[2] = toc.FirstTrack
+	b[3] = toc.LastTrack
+
+	descriptor := b[tocHeaderSize:]
+	for _, track := range toc.Tracks {
+		descriptor[1] = track.Control
+		descriptor[2] = track.Number
+		binary.BigEndian.PutUint32(descriptor[4:], track.Offset)
+		descriptor = descriptor[tocTrackSize:]
+	}
+
+	descriptor[1] = tocLeadOutControl
+	descriptor[2] = tocLeadOut
+	binary.BigEndian.PutUint32(descriptor[4:], toc.LeadOut)
+
+	return b
+}
+
+// Decodes a binary table of contents
+func ReadTableOfContents(data []byte) (*TableOfContents, error) {
+	if len(data) < tocHeaderSize {
+		return nil, truncatedError(len(data), tocHeaderSize)
+	}
+
+	size := int(binary.BigEndian.Uint16(data)) + 2
+	if len(data) < size {
+		return nil, truncatedError(len(data), size)
+	}
+
+	if (size-tocHeaderSize)%tocTrackSize != 0 {
+		return nil, fmt.Errorf("toc: %d bytes of track descriptors", size-tocHeaderSize)
+	}
+
+	toc := &TableOfContents{
+		FirstTrack: data[2],
+		LastTrack:  data[3],
+	}
+
+	leadOut := false
+	for descriptor := data[tocHeaderSize:size]; len(descriptor) > 0; descriptor = descriptor[tocTrackSize:] {
+		track := TOCTrack{
+			Number:  descriptor[2],
+			Control: descriptor[1],
+			Offset:  binary.BigEndian.Uint32(descriptor[4:]),
+		}
+
+		if track.Number == tocLeadOut {
+			toc.LeadOut = track.Offset
+			leadOut = true
+			break
+		}
+
+		toc.Tracks = append(toc.Tracks, track)
+	}
+
+	if !leadOut {
+		return nil, errors.New("toc: no lead-out area")
+	}
+
+	return toc, nil
+}
+
+// MCDIFrame represents music CD identifier frames, which hold the table of
+// contents of the CD the audio is from
+// Some taggers store the table of contents in other forms, so the data is
+// only decoded when it is asked for
+type MCDIFrame struct {
+	DataFrame
+}
+
+func NewMCDIFrame(ft FrameType, toc TableOfContents) *MCDIFrame {
+	return &MCDIFrame{*NewDataFrame(ft, toc.Bytes())}
+}
+
+func ParseMCDIFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadMCDIFrame(head, data))
+}
+
+func ReadMCDIFrame(head FrameHead, data []byte) (Framer, error) {
+	return &MCDIFrame{DataFrame{head, data}}, nil
+}
+
+func (f MCDIFrame) TableOfContents() (*TableOfContents, error) {
+	return ReadTableOfContents(f.data)
+}
+
+func (f *MCDIFrame) SetTableOfContents(toc TableOfContents) {
+	f.SetData(toc.Bytes())
+}
+
+func (f MCDIFrame) String() string {
+	toc, err := f.TableOfContents()
+	if err != nil {
+		return f.DataFrame.String()
+	}
+
+	return fmt.Sprintf("%d tracks, lead-out at %d", toc.TrackCount(), toc.LeadOut)
+}
+
+// Unique identifier of the file in the database of an owner
+func (t Tag) UniqueID(ownerId string) ([]byte, bool) {
+	for _, frame := range t.frames {
+		if f, ok := frame.(*IdFrame); ok && f.OwnerIdentifier() == ownerId {
+			return f.Identifier(), true
+		}
+	}
+
+	return nil, false
+}
+
+// Sets the unique identifier of the file in the database of an owner
+// A tag has only one identifier of each owner, so other frames of the owner
+// are deleted
+func (t *Tag) SetUniqueID(ownerId string, id []byte) error {
+	ft, ok := t.commonMap["UniqueID"]
+	if !ok {
+		return errors.New("set unique id: unique file identifiers are not supported in this version")
+	}
+
+	if ownerId == "" {
+		return errors.New("set unique id: empty owner identifier")
+	}
+
+	if len(id) > maxUniqueIDSize {
+		return fmt.Errorf("set unique id: identifier of %d bytes is longer than %d bytes", len(id), maxUniqueIDSize)
+	}
+
+	var existing *IdFrame
+	for i := 0; i < len(t.frames); {
+		f, ok := t.frames[i].(*IdFrame)
+		if !ok || f.OwnerIdentifier() != ownerId {
+			i++
+			continue
+		}
+
+		if existing == nil {
+			existing = f
+			i++
+			continue
+		}
+
+		t.changeSize(-(t.frameHeaderSize + int(f.Size())))
+		f.setOwner(nil)
+		t.frames = append(t.frames[:i], t.frames[i+1:]...)
+	}
+
+	if existing != nil {
+		return existing.SetIdentifier(id)
+	}
+
+	t.AddFrames(NewIdFrame(ft, ownerId, id))
+	return nil
+}
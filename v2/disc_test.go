@@ -0,0 +1,90 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestMCDIFrame(t *testing.T) {
+	data := []byte{
+		0, 26, 1, 2,
+		0, 0x10, 1, 0, 0, 0, 0, 0,
+		0, 0x10, 2, 0, 0, 0, 0x46, 0x50,
+		0, 0x10, 0xaa, 0, 0, 0, 0x8c, 0xa0,
+	}
+
+	frame, err := ReadMCDIFrame(FrameHead{FrameType: V23FrameTypeMap["MCDI"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toc, err := frame.(*MCDIFrame).TableOfContents()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &TableOfContents{
+		FirstTrack: 1,
+		LastTrack:  2,
+		Tracks:     []TOCTrack{{1, 0x10, 0}, {2, 0x10, 18000}},
+		LeadOut:    36000,
+	}
+
+	if !reflect.DeepEqual(toc, expected) || toc.TrackCount() != 2 {
+		t.Errorf("TableOfContents incorrect, %v", toc)
+	}
+
+	f := NewMCDIFrame(V24FrameTypeMap["MCDI"], *expected)
+	if b := f.Bytes(); !bytes.Equal(b, data) || int(f.Size()) != len(data) {
+		t.Errorf("NewMCDIFrame produces %v, expected %v", b, data)
+	}
+
+	// Tables of contents in other forms are kept but cannot be decoded
+	frame, err = ReadMCDIFrame(FrameHead{FrameType: V23FrameTypeMap["MCDI"], size: 4}, []byte("1+96"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := frame.(*MCDIFrame).TableOfContents(); err == nil {
+		t.Errorf("TableOfContents decoded text data")
+	}
+}
+
+func TestTagUniqueID(t *testing.T) {
+	owner := "http://musicbrainz.org"
+	id := []byte("b4c1f8d3-6b2d-4f0d-9c7e-5d2a1e3f4a6b")
+
+	tag := NewTag(3)
+	tag.AddFrames(NewIdFrame(V23FrameTypeMap["UFID"], owner, []byte("old")))
+	tag.AddFrames(NewIdFrame(V23FrameTypeMap["UFID"], owner, []byte("duplicate")))
+	tag.AddFrames(NewIdFrame(V23FrameTypeMap["UFID"], "other", []byte("1")))
+
+	if err := tag.SetUniqueID(owner, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tag.SetUniqueID(owner, bytes.Repeat([]byte{1}, 65)); err == nil {
+		t.Errorf("SetUniqueID accepted an identifier longer than 64 bytes")
+	}
+
+	parsed, err := ReadTag(bytes.NewReader(tag.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(parsed.Frames("UFID")); n != 2 {
+		t.Errorf("tag has %d unique file identifiers", n)
+	}
+
+	if got, ok := parsed.UniqueID(owner); !ok || !bytes.Equal(got, id) {
+		t.Errorf("incorrect unique id %s", got)
+	}
+
+	if got, ok := parsed.UniqueID("other"); !ok || !bytes.Equal(got, []byte("1")) {
+		t.Errorf("incorrect unique id of other owner %s", got)
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"strings"
+	"sync"
+)
+
+const (
+	// Size of the preview fields of an audio encryption frame
+	previewSize = 2 + 2
+)
+
+// FrameCipher encrypts and decrypts the data of frames with an encryption
+// method
+// Frames are compressed before they are encrypted, so a cipher sees the data
+// as it is stored in the tag
+type FrameCipher interface {
+	Encrypt(data []byte) ([]byte, error)
+	Decrypt(data []byte) ([]byte, error)
+}
+
+var (
+	frameCiphersMu sync.RWMutex
+	frameCiphers   = make(map[string]FrameCipher)
+
+	// Changes whenever a cipher is registered or removed
+	frameCiphersChanges uint64
+)
+
+// Registers the cipher of an encryption method by the owner identifier of
+// its encryption method registration frames, or removes it with a nil cipher
+// Each tag ties its own method symbols to owners with registration frames, so
+// a symbol can stand for different methods in different tags
+// Frames encrypted with a method that has a cipher are decrypted when they are
+// read and encrypted again when they are written
+// Frames encrypted with other methods are kept as opaque data
+func RegisterFrameCipher(ownerId string, cipher FrameCipher) {
+	frameCiphersMu.Lock()
+	defer frameCiphersMu.Unlock()
+
+	frameCiphersChanges++
+	if cipher == nil {
+		delete(frameCiphers, ownerId)
+		return
+	}
+
+	frameCiphers[ownerId] = cipher
+}
+
+func registeredFrameCipher(ownerId string) (FrameCipher, bool) {
+	frameCiphersMu.RLock()
+	defer frameCiphersMu.RUnlock()
+
+	cipher, ok := frameCiphers[ownerId]
+	return cipher, ok
+}
+
+func frameCiphersGeneration() uint64 {
+	frameCiphersMu.RLock()
+	defer frameCiphersMu.RUnlock()
+
+	return frameCiphersChanges
+}
+
+// Cipher of an encryption method symbol, found through the encryption method
+// registration frames of the tag
+func (t Tag) frameCipher(method byte) (FrameCipher, bool) {
+	f := t.EncryptionRegistration(method)
+	if f == nil {
+		return nil, false
+	}
+
+	return registeredFrameCipher(f.OwnerIdentifier())
+}
+
+// Cipher of an encryption method symbol, if frames are encrypted with the
+// options
+func (opts frameOptions) frameCipher(method byte) (FrameCipher, bool) {
+	if opts.cipher == nil {
+		return nil, false
+	}
+
+	return opts.cipher(method)
+}
+
+// Encrypts frame data for a frame that is encrypted when it is written
+func encryptFrameData(f Framer, data []byte, opts frameOptions) ([]byte, error) {
+	method, _ := f.EncryptionMethod()
+
+	cipher, ok := opts.frameCipher(method)
+	if !ok {
+		return nil, fmt.Errorf("encryption: no cipher for method 0x%02x", method)
+	}
+
+	return cipher.Encrypt(data)
+}
+
+// Decrypts the frames that were read as opaque data
+// Encryption methods are registered by frames that can come after the frames
+// they encrypt, so frames are decrypted once all of the frames have been read
+func (t *Tag) decryptFrames(strict bool) error {
+	var flag byte
+	switch t.version {
+	case 3:
+		flag = V23FlagEncryption
+	case 4:
+		flag = V24FlagEncryption
+	default:
+		return nil
+	}
+
+	opts := frameOptions{strict: strict, cipher: t.frameCipher}
+	for i, frame := range t.frames {
+		if !isBitSet(frame.FormatFlags(), flag) {
+			continue
+		}
+
+		data, err := t.frameBytesConstructor(frame, frameOptions{})
+		if err != nil {
+			return err
+		}
+
+		decrypted, err := t.frameConstructor(bytes.NewReader(data), opts)
+		if err != nil {
+			return err
+		}
+
+		decrypted.setOwner(t)
+		t.frames[i] = decrypted
+	}
+
+	return nil
+}
+
+// AudioEncryptionFrame represents audio encryption frames, which describe how
+// the audio itself is encrypted and which part of it can be played without
+// decrypting it
+type AudioEncryptionFrame struct {
+	DataFrame
+	ownerIdentifier string
+	previewStart    uint16
+	previewLength   uint16
+}
+
+func NewAudioEncryptionFrame(ft FrameType, ownerId string, previewStart, previewLength uint16, data []byte) *AudioEncryptionFrame {
+	ownerId = strings.TrimRight(ownerId, "\x00")
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(len(ownerId) + 1 + previewSize + len(data)),
+	}
+
+	return &AudioEncryptionFrame{
+		DataFrame:       DataFrame{head, data},
+		ownerIdentifier: ownerId,
+		previewStart:    previewStart,
+		previewLength:   previewLength,
+	}
+}
+
+func ParseAudioEncryptionFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadAudioEncryptionFrame(head, data))
+}
+
+func ReadAudioEncryptionFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := new(AudioEncryptionFrame)
+	f.FrameHead = head
+	rd := encodedbytes.NewReader(data)
+
+	if f.ownerIdentifier, err = rd.ReadNullTermString(encodedbytes.NativeEncoding); err != nil {
+		return nil, err
+	}
+
+	preview, err := rd.ReadNumBytes(previewSize)
+	if err != nil {
+		return nil, truncatedError(rd.Len(), previewSize)
+	}
+
+	f.previewStart = binary.BigEndian.Uint16(preview[0:2])
+	f.previewLength = binary.BigEndian.Uint16(preview[2:4])
+
+	if f.data, err = rd.ReadRest(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f AudioEncryptionFrame) OwnerIdentifier() string {
+	return f.ownerIdentifier
+}
+
+func (f *AudioEncryptionFrame) SetOwnerIdentifier(ownerId string) {
+	ownerId = strings.TrimRight(ownerId, "\x00")
+
+	f.changeSize(len(ownerId) - len(f.ownerIdentifier))
+	f.ownerIdentifier = ownerId
+}
+
+// Unencrypted part of the audio, as a start and a length in MPEG frames
+// A zero length means the audio has no unencrypted part
+func (f AudioEncryptionFrame) Preview() (start, length uint16) {
+	return f.previewStart, f.previewLength
+}
+
+func (f *AudioEncryptionFrame) SetPreview(start, length uint16) {
+	f.previewStart, f.previewLength = start, length
+	f.changeSize(0)
+}
+
+func (f AudioEncryptionFrame) String() string {
+	return fmt.Sprintf("%s: preview of %d frames from %d", f.ownerIdentifier, f.previewLength, f.previewStart)
+}
+
+func (f AudioEncryptionFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteNullTermString(f.ownerIdentifier, encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	preview := make([]byte, previewSize)
+	binary.BigEndian.PutUint16(preview[0:2], f.previewStart)
+	binary.BigEndian.PutUint16(preview[2:4], f.previewLength)
+
+	if n, err := wr.Write(preview); n < len(preview) || err != nil {
+		return bytes
+	}
+
+	if n, err := wr.Write(f.data); n < len(f.data) || err != nil {
+		return bytes
+	}
+
+	return bytes
+}
+
+// symbolFrame holds the owner identifier and the symbol that are shared by
+// encryption method and group identification registration frames
+type symbolFrame struct {
+	DataFrame
+	ownerIdentifier string
+	symbol          byte
+}
+
+func newSymbolFrame(ft FrameType, ownerId string, symbol byte, data []byte) symbolFrame {
+	ownerId = strings.TrimRight(ownerId, "\x00")
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(len(ownerId) + 1 + 1 + len(data)),
+	}
+
+	return symbolFrame{DataFrame{head, data}, ownerId, symbol}
+}
+
+func readSymbolFrame(head FrameHead, data []byte) (symbolFrame, error) {
+	var err error
+	f := symbolFrame{}
+	f.FrameHead = head
+	rd := encodedbytes.NewReader(data)
+
+	if f.ownerIdentifier, err = rd.ReadNullTermString(encodedbytes.NativeEncoding); err != nil {
+		return f, err
+	}
+
+	if f.symbol, err = rd.ReadByte(); err != nil {
+		return f, truncatedError(0, 1)
+	}
+
+	if f.data, err = rd.ReadRest(); err != nil {
+		return f, err
+	}
+
+	return f, nil
+}
+
+func (f symbolFrame) OwnerIdentifier() string {
+	return f.ownerIdentifier
+}
+
+func (f *symbolFrame) SetOwnerIdentifier(ownerId string) {
+	ownerId = strings.TrimRight(ownerId, "\x00")
+
+	f.changeSize(len(ownerId) - len(f.ownerIdentifier))
+	f.ownerIdentifier = ownerId
+}
+
+func (f symbolFrame) String() string {
+	return fmt.Sprintf("0x%02x %s: <binary data>", f.symbol, f.ownerIdentifier)
+}
+
+func (f symbolFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteNullTermString(f.ownerIdentifier, encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteByte(f.symbol); err != nil {
+		return bytes
+	}
+
+	if n, err := wr.Write(f.data); n < len(f.data) || err != nil {
+		return bytes
+	}
+
+	return bytes
+}
+
+// EncryptionMethodFrame represents encryption method registration frames,
+// which tie the method symbol in the headers of encrypted frames to the
+// owner of the encryption method
+type EncryptionMethodFrame struct {
+	symbolFrame
+}
+
+func NewEncryptionMethodFrame(ft FrameType, ownerId string, method byte, data []byte) *EncryptionMethodFrame {
+	return &EncryptionMethodFrame{newSymbolFrame(ft, ownerId, method, data)}
+}
+
+func ParseEncryptionMethodFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadEncryptionMethodFrame(head, data))
+}
+
+func ReadEncryptionMethodFrame(head FrameHead, data []byte) (Framer, error) {
+	f, err := readSymbolFrame(head, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptionMethodFrame{f}, nil
+}
+
+func (f EncryptionMethodFrame) Method() byte {
+	return f.symbol
+}
+
+func (f *EncryptionMethodFrame) SetMethod(method byte) {
+	f.symbol = method
+	f.changeSize(0)
+}
+
+// GroupIdFrame represents group identification registration frames, which
+// tie the group identifier in the headers of grouped frames to the owner of
+// the grouping
+type GroupIdFrame struct {
+	symbolFrame
+}
+
+func NewGroupIdFrame(ft FrameType, ownerId string, groupId byte, data []byte) *GroupIdFrame {
+	return &GroupIdFrame{newSymbolFrame(ft, ownerId, groupId, data)}
+}
+
+func ParseGroupIdFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadGroupIdFrame(head, data))
+}
+
+func ReadGroupIdFrame(head FrameHead, data []byte) (Framer, error) {
+	f, err := readSymbolFrame(head, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GroupIdFrame{f}, nil
+}
+
+func (f GroupIdFrame) GroupSymbol() byte {
+	return f.symbol
+}
+
+func (f *GroupIdFrame) SetGroupSymbol(groupId byte) {
+	f.symbol = groupId
+	f.changeSize(0)
+}
+
+// Frames that belong to a group, in the order they are written
+func (t Tag) GroupFrames(groupId byte) []Framer {
+	frames := []Framer{}
+	for _, frame := range t.frames {
+		if id, ok := frame.GroupId(); ok && id == groupId {
+			frames = append(frames, frame)
+		}
+	}
+
+	return frames
+}
+
+// Encryption method registration frame of a method symbol, or nil if the tag
+// does not register the method
+func (t Tag) EncryptionRegistration(method byte) *EncryptionMethodFrame {
+	for _, frame := range t.frames {
+		if f, ok := frame.(*EncryptionMethodFrame); ok && f.Method() == method {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// Group identification registration frame of a group, or nil if the tag does
+// not register the group
+func (t Tag) GroupRegistration(groupId byte) *GroupIdFrame {
+	for _, frame := range t.frames {
+		if f, ok := frame.(*GroupIdFrame); ok && f.GroupSymbol() == groupId {
+			return f
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type xorCipher byte
+
+func (c xorCipher) Encrypt(data []byte) ([]byte, error) {
+	encrypted := make([]byte, len(data))
+	for i, b := range data {
+		encrypted[i] = b ^ byte(c)
+	}
+
+	return encrypted, nil
+}
+
+func (c xorCipher) Decrypt(data []byte) ([]byte, error) {
+	return c.Encrypt(data)
+}
+
+type countingCipher struct {
+	xorCipher
+	encrypted int
+}
+
+func (c *countingCipher) Encrypt(data []byte) ([]byte, error) {
+	c.encrypted++
+	return c.xorCipher.Encrypt(data)
+}
+
+type failingCipher struct{}
+
+func (failingCipher) Encrypt(data []byte) ([]byte, error) {
+	return nil, errors.New("no key")
+}
+
+func (failingCipher) Decrypt(data []byte) ([]byte, error) {
+	return nil, errors.New("no key")
+}
+
+func TestRegistrationFrames(t *testing.T) {
+	data := []byte("owner\x00\x00\x10\x00\x40key")
+	frame, err := ReadAudioEncryptionFrame(FrameHead{FrameType: V23FrameTypeMap["AENC"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aenc := frame.(*AudioEncryptionFrame)
+	if start, length := aenc.Preview(); aenc.OwnerIdentifier() != "owner" || start != 0x10 || length != 0x40 || !bytes.Equal(aenc.Data(), []byte("key")) {
+		t.Errorf("ReadAudioEncryptionFrame incorrect frame, %v", aenc)
+	}
+
+	if b := aenc.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	data = []byte("owner\x00\x81\x01\x02")
+	frame, err = ReadGroupIdFrame(FrameHead{FrameType: V24FrameTypeMap["GRID"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grid := frame.(*GroupIdFrame)
+	if grid.OwnerIdentifier() != "owner" || grid.GroupSymbol() != 0x81 || !bytes.Equal(grid.Data(), []byte{1, 2}) {
+		t.Errorf("ReadGroupIdFrame incorrect frame, %v", grid)
+	}
+
+	if b := grid.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	encr := NewEncryptionMethodFrame(V24FrameTypeMap["ENCR"], "owner", 0x80, nil)
+	encr.SetOwnerIdentifier("o")
+	if b := encr.Bytes(); len(b) != int(encr.Size()) || !bytes.Equal(b, []byte{'o', 0, 0x80}) {
+		t.Errorf("EncryptionMethodFrame produces %v", b)
+	}
+}
+
+func TestFrameCipher(t *testing.T) {
+	RegisterFrameCipher("owner", xorCipher(0x5a))
+	defer RegisterFrameCipher("owner", nil)
+
+	for _, version := range []byte{3, 4} {
+		tag := NewTag(version)
+		tag.SetFrameCompression(true)
+		tag.SetTitle("Preview")
+
+		// Lyrics are compressed before they are encrypted
+		text := strings.Repeat("Hidden lyrics\n", 20)
+		lyrics := NewUnsynchTextFrame(V24FrameTypeMap["USLT"], "Secret", text)
+		lyrics.SetGroupId(0x81, true)
+		if err := lyrics.SetEncryptionMethod(0x80, true); err != nil {
+			t.Fatal(err)
+		}
+
+		tag.AddFrames(NewEncryptionMethodFrame(V24FrameTypeMap["ENCR"], "owner", 0x80, nil), lyrics)
+
+		data := tag.Bytes()
+		if bytes.Contains(data, []byte("Hidden")) {
+			t.Errorf("v2.%d encrypted frame written in the clear", version)
+		}
+
+		parsed, err := ReadTag(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		grouped := parsed.GroupFrames(0x81)
+		if len(grouped) != 1 {
+			t.Fatalf("v2.%d GroupFrames found %d frames", version, len(grouped))
+		}
+
+		f, ok := grouped[0].(*UnsynchTextFrame)
+		if !ok || f.Text() != text {
+			t.Errorf("v2.%d incorrect decrypted frame %v", version, grouped[0])
+		}
+
+		if method, ok := grouped[0].EncryptionMethod(); !ok || method != 0x80 {
+			t.Errorf("v2.%d incorrect encryption method 0x%02x", version, method)
+		}
+
+		// Without the cipher, the frame is kept as it was read
+		RegisterFrameCipher("owner", nil)
+		parsed, err = ReadTag(bytes.NewReader(data))
+		RegisterFrameCipher("owner", xorCipher(0x5a))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := parsed.Frame(f.Id()).(*DataFrame); !ok {
+			t.Errorf("v2.%d frame with an unknown method is %T", version, parsed.Frame(f.Id()))
+		}
+
+		if b := parsed.Bytes(); !bytes.Equal(b, data) {
+			t.Errorf("v2.%d frame with an unknown method is not written as it was read", version)
+		}
+	}
+
+	tag := NewTag(4)
+	tag.SetTitle("Preview")
+	if err := tag.Frame("TIT2").SetEncryptionMethod(0x90, true); err == nil {
+		t.Errorf("SetEncryptionMethod succeeded without a registered method")
+	}
+}
+
+func TestFrameCipherPerTag(t *testing.T) {
+	RegisterFrameCipher("first", xorCipher(0x5a))
+	RegisterFrameCipher("second", xorCipher(0xa5))
+	defer RegisterFrameCipher("first", nil)
+	defer RegisterFrameCipher("second", nil)
+
+	// The same method symbol stands for a different method in each tag
+	var data [][]byte
+	for _, owner := range []string{"first", "second"} {
+		tag := NewTag(4)
+		tag.AddFrames(NewEncryptionMethodFrame(V24FrameTypeMap["ENCR"], owner, 0x80, nil))
+		tag.SetTitle(owner)
+		if err := tag.Frame("TIT2").SetEncryptionMethod(0x80, true); err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := tag.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data = append(data, b)
+	}
+
+	for i, owner := range []string{"first", "second"} {
+		parsed, err := ReadTag(bytes.NewReader(data[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s := parsed.Title(); s != owner {
+			t.Errorf("tag encrypted by %s has title %q", owner, s)
+		}
+	}
+
+	// Frames can come before the frame that registers their method
+	tag := NewTag(3)
+	tag.SetTitle("Early")
+	tag.AddFrames(NewEncryptionMethodFrame(V23FrameTypeMap["ENCR"], "first", 0x80, nil))
+	if err := tag.Frame("TIT2").SetEncryptionMethod(0x80, true); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ReadTag(bytes.NewReader(tag.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := parsed.Title(); s != "Early" {
+		t.Errorf("frame encrypted before its registration has title %q", s)
+	}
+}
+
+func TestFrameCipherFailure(t *testing.T) {
+	RegisterFrameCipher("owner", failingCipher{})
+	defer RegisterFrameCipher("owner", nil)
+
+	tag := NewTag(4)
+	tag.AddFrames(NewEncryptionMethodFrame(V24FrameTypeMap["ENCR"], "owner", 0x80, nil))
+	tag.SetTitle("Secret")
+	if err := tag.Frame("TIT2").SetEncryptionMethod(0x80, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tag.MarshalBinary(); err == nil {
+		t.Error("MarshalBinary succeeded with a frame that cannot be encrypted")
+	}
+
+	if data := tag.Bytes(); bytes.Contains(data, []byte("Secret")) {
+		t.Error("Bytes wrote a frame that cannot be encrypted in the clear")
+	}
+}
+
+func TestFrameCipherSizeQueries(t *testing.T) {
+	cipher := &countingCipher{xorCipher: 0x5a}
+	RegisterFrameCipher("owner", cipher)
+	defer RegisterFrameCipher("owner", nil)
+
+	tag := NewTag(4)
+	tag.AddFrames(NewEncryptionMethodFrame(V24FrameTypeMap["ENCR"], "owner", 0x80, nil))
+	tag.SetTitle("Secret")
+	if err := tag.Frame("TIT2").SetEncryptionMethod(0x80, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// The frames are encrypted once until the tag is modified
+	tag.Size()
+	tag.RealSize()
+	tag.Padding()
+	tag.Bytes()
+	if cipher.encrypted != 1 {
+		t.Errorf("frame encrypted %d times", cipher.encrypted)
+	}
+
+	tag.SetTitle("Another secret")
+	if data := tag.Bytes(); cipher.encrypted != 2 || bytes.Contains(data, []byte("secret")) {
+		t.Errorf("modified frame encrypted %d times", cipher.encrypted)
+	}
+}
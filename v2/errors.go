@@ -0,0 +1,74 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrNoTag is returned when the data does not start with an ID3v2 tag
+	ErrNoTag = errors.New("id3v2: no tag")
+
+	// ErrInvalidHeader is returned when the tag header is malformed or of an
+	// unsupported version
+	ErrInvalidHeader = errors.New("id3v2: invalid header")
+
+	// ErrTruncated is returned when the data ends before a header, frame or
+	// field is complete
+	ErrTruncated = errors.New("id3v2: truncated data")
+
+	// ErrInvalidFrameId is returned when a frame header has an ID that is
+	// not made of capital letters and digits
+	ErrInvalidFrameId = errors.New("id3v2: invalid frame id")
+
+	// ErrInvalidEncoding is returned when a text encoding byte is not one
+	// of the encodings defined by the specification
+	ErrInvalidEncoding = errors.New("id3v2: invalid encoding")
+
+	// ErrCRCMismatch is returned when the CRC-32 in the extended header does
+	// not match the tag data
+	ErrCRCMismatch = errors.New("id3v2: CRC mismatch")
+
+	// ErrInvalidURL is returned when a URL cannot be written to a URL frame
+	ErrInvalidURL = errors.New("id3v2: invalid URL")
+)
+
+// FrameError records a failure to read a frame
+type FrameError struct {
+	// ID of the frame, empty if the frame header could not be read
+	Id string
+	// Offset of the frame header from the start of the tag, or from the
+	// start of the reader when the frame is read on its own
+	Offset int64
+	Err    error
+}
+
+func (e *FrameError) Error() string {
+	id := e.Id
+	if id == "" {
+		id = "<unknown>"
+	}
+
+	return fmt.Sprintf("id3v2: frame %s at offset %d: %v", id, e.Offset, e.Err)
+}
+
+func (e *FrameError) Unwrap() error {
+	return e.Err
+}
+
+// Wraps an error from reading a frame, normalizing end of data errors
+func newFrameError(id string, err error) *FrameError {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = ErrTruncated
+	}
+
+	return &FrameError{Id: id, Err: err}
+}
+
+func truncatedError(read, size int) error {
+	return fmt.Errorf("%w: read %d of %d bytes", ErrTruncated, read, size)
+}
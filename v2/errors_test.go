@@ -0,0 +1,79 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestReadV23FrameInvalidEncoding(t *testing.T) {
+	data := []byte{84, 73, 84, 50, 0, 0, 0, 3, 0, 0, 7, 72, 105}
+
+	_, err := ReadV23Frame(bytes.NewReader(data))
+	if !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("ReadV23Frame returned %v, expected invalid encoding", err)
+	}
+
+	var frameErr *FrameError
+	if !errors.As(err, &frameErr) || frameErr.Id != "TIT2" {
+		t.Errorf("ReadV23Frame returned %v, expected frame error for TIT2", err)
+	}
+
+	if frame := ParseV23Frame(bytes.NewReader(data)); frame == nil {
+		t.Errorf("ParseV23Frame did not fall back to a data frame")
+	}
+}
+
+func TestReadV23FrameTruncated(t *testing.T) {
+	data := []byte{84, 73, 84, 50, 0, 0, 0, 13, 0, 0, 0, 72, 105}
+
+	if _, err := ReadV23Frame(bytes.NewReader(data)); !errors.Is(err, ErrTruncated) {
+		t.Errorf("ReadV23Frame returned %v, expected truncated", err)
+	}
+
+	if _, err := ReadV23Frame(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("ReadV23Frame on empty reader returned %v, expected EOF", err)
+	}
+}
+
+func TestReadTagFrameOffset(t *testing.T) {
+	tag := NewTag(3)
+	tag.AddFrames(NewTextFrame(V23FrameTypeMap["TIT2"], "Nice Life"))
+	data := tag.Bytes()
+
+	// Append a frame with an invalid ID after the first frame
+	offset := len(data)
+	bad := []byte{116, 105, 116, 50, 0, 0, 0, 1, 0, 0, 0}
+	data = append(data, bad...)
+	copy(data[6:HeaderSize], []byte{0, 0, 0, byte(offset - HeaderSize + len(bad))})
+
+	_, err := ReadTag(bytes.NewReader(data))
+
+	var frameErr *FrameError
+	if !errors.As(err, &frameErr) || !errors.Is(err, ErrInvalidFrameId) {
+		t.Fatalf("ReadTag returned %v, expected invalid frame id", err)
+	}
+
+	if frameErr.Offset != int64(offset) {
+		t.Errorf("ReadTag returned offset %d, expected %d", frameErr.Offset, offset)
+	}
+
+	if parsed := ParseTag(bytes.NewReader(data)); parsed == nil || parsed.Title() != "Nice Life" {
+		t.Errorf("ParseTag did not keep frames before the invalid frame")
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	if _, err := ReadHeader(bytes.NewReader([]byte("TAG"))); err != ErrNoTag {
+		t.Errorf("ReadHeader returned %v, expected no tag", err)
+	}
+
+	data := []byte{73, 68, 51, 3, 0, 0, 0, 0, 0x80, 0}
+	if _, err := ReadHeader(bytes.NewReader(data)); !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("ReadHeader returned %v, expected invalid header", err)
+	}
+}
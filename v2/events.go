@@ -0,0 +1,221 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"strings"
+)
+
+// EventType is the type of a timed event
+type EventType byte
+
+const (
+	EventPadding EventType = iota
+	EventInitialSilenceEnd
+	EventIntroStart
+	EventMainPartStart
+	EventOutroStart
+	EventOutroEnd
+	EventVerseStart
+	EventRefrainStart
+	EventInterludeStart
+	EventThemeStart
+	EventVariationStart
+	EventKeyChange
+	EventTimeChange
+	EventMomentaryNoise
+	EventSustainedNoise
+	EventSustainedNoiseEnd
+	EventIntroEnd
+	EventMainPartEnd
+	EventVerseEnd
+	EventRefrainEnd
+	EventThemeEnd
+	EventProfanity
+	EventProfanityEnd
+)
+
+const (
+	// Events without a predefined meaning, 0xE0 to 0xEF
+	EventSync EventType = 0xe0
+
+	EventAudioEnd     EventType = 0xfd
+	EventAudioFileEnd EventType = 0xfe
+)
+
+var eventTypeDescriptions = [...]string{
+	"Padding",
+	"End of initial silence",
+	"Intro start",
+	"Main part start",
+	"Outro start",
+	"Outro end",
+	"Verse start",
+	"Refrain start",
+	"Interlude start",
+	"Theme start",
+	"Variation start",
+	"Key change",
+	"Time change",
+	"Momentary unwanted noise",
+	"Sustained noise",
+	"Sustained noise end",
+	"Intro end",
+	"Main part end",
+	"Verse end",
+	"Refrain end",
+	"Theme end",
+	"Profanity",
+	"Profanity end",
+}
+
+func (e EventType) String() string {
+	switch {
+	case int(e) < len(eventTypeDescriptions):
+		return eventTypeDescriptions[e]
+	case e >= EventSync && e <= EventSync+0xf:
+		return fmt.Sprintf("Sync %X", byte(e-EventSync))
+	case e == EventAudioEnd:
+		return "Audio end"
+	case e == EventAudioFileEnd:
+		return "Audio file end"
+	}
+
+	return "Unknown"
+}
+
+// Event is an event at a point in time
+type Event struct {
+	Type EventType
+	Time uint32
+}
+
+// EventTimingFrame represents frames that mark events in the audio, such as
+// the start of the main part
+type EventTimingFrame struct {
+	FrameHead
+	format TimestampFormat
+	events []Event
+}
+
+// Creates an event timing frame with timestamps in milliseconds
+func NewEventTimingFrame(ft FrameType, events []Event) *EventTimingFrame {
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(1 + len(events)*(1+timestampSize)),
+	}
+
+	return &EventTimingFrame{head, TimestampMilliseconds, append([]Event(nil), events...)}
+}
+
+func ParseEventTimingFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadEventTimingFrame(head, data))
+}
+
+func ReadEventTimingFrame(head FrameHead, data []byte) (Framer, error) {
+	f := &EventTimingFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	format, err := rd.ReadByte()
+	if err != nil {
+		return nil, truncatedError(len(data), 1)
+	}
+
+	f.format = TimestampFormat(format)
+
+	for rd.Len() > 0 {
+		var event Event
+
+		eventType, _ := rd.ReadByte()
+		event.Type = EventType(eventType)
+
+		if event.Time, err = readTimestamp(rd); err != nil {
+			return nil, err
+		}
+
+		f.events = append(f.events, event)
+	}
+
+	return f, nil
+}
+
+func (f EventTimingFrame) TimestampFormat() TimestampFormat {
+	return f.format
+}
+
+// Sets the unit of the timestamps
+// The timestamps themselves are not converted
+func (f *EventTimingFrame) SetTimestampFormat(format TimestampFormat) {
+	f.format = format
+	f.changeSize(0)
+}
+
+// Events in the order of the frame
+func (f EventTimingFrame) Events() []Event {
+	return append([]Event(nil), f.events...)
+}
+
+func (f *EventTimingFrame) SetEvents(events []Event) {
+	f.changeSize((len(events) - len(f.events)) * (1 + timestampSize))
+	f.events = append([]Event(nil), events...)
+}
+
+// Adds an event after the events that are not later than it, keeping the
+// events in chronological order
+func (f *EventTimingFrame) AddEvent(event Event) {
+	i := 0
+	for i < len(f.events) && f.events[i].Time <= event.Time {
+		i++
+	}
+
+	events := make([]Event, 0, len(f.events)+1)
+	events = append(events, f.events[:i]...)
+	events = append(events, event)
+	f.SetEvents(append(events, f.events[i:]...))
+}
+
+// Removes all events of a type
+func (f *EventTimingFrame) DeleteEvents(eventType EventType) {
+	var events []Event
+	for _, event := range f.events {
+		if event.Type != eventType {
+			events = append(events, event)
+		}
+	}
+
+	f.SetEvents(events)
+}
+
+func (f EventTimingFrame) String() string {
+	events := make([]string, len(f.events))
+	for i, event := range f.events {
+		events[i] = fmt.Sprintf("%d: %s", event.Time, event.Type)
+	}
+
+	return strings.Join(events, "\n")
+}
+
+func (f EventTimingFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteByte(byte(f.format)); err != nil {
+		return bytes
+	}
+
+	for _, event := range f.events {
+		if err = wr.WriteByte(byte(event.Type)); err != nil {
+			return bytes
+		}
+
+		if _, err = wr.Write(encodedbytes.NormBytes(event.Time)); err != nil {
+			return bytes
+		}
+	}
+
+	return bytes
+}
@@ -0,0 +1,46 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEventTimingFrame(t *testing.T) {
+	data := []byte{2, 0x02, 0, 0, 0, 0, 0x03, 0, 0, 0x3a, 0x98}
+
+	frame, err := ReadEventTimingFrame(FrameHead{FrameType: V23FrameTypeMap["ETCO"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*EventTimingFrame)
+	expected := []Event{{EventIntroStart, 0}, {EventMainPartStart, 15000}}
+	if f.TimestampFormat() != TimestampMilliseconds || !reflect.DeepEqual(f.Events(), expected) {
+		t.Errorf("ReadEventTimingFrame incorrect frame, %v", f)
+	}
+
+	if b := f.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	f.AddEvent(Event{EventKeyChange, 7000})
+	f.AddEvent(Event{EventSync + 1, 7000})
+	f.DeleteEvents(EventIntroStart)
+
+	expected = []Event{{EventKeyChange, 7000}, {EventSync + 1, 7000}, {EventMainPartStart, 15000}}
+	if !reflect.DeepEqual(f.Events(), expected) {
+		t.Errorf("incorrect events after editing %v", f.Events())
+	}
+
+	if b := f.Bytes(); len(b) != int(f.Size()) || len(b) != 1+3*5 {
+		t.Errorf("edited frame has %d bytes and size %d", len(b), f.Size())
+	}
+
+	if s := (EventSync + 1).String(); s != "Sync 1" {
+		t.Errorf("incorrect event type description %s", s)
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"hash/crc32"
+)
+
+const (
+	// Sizes of the ID3v2.3 extended header, which has an optional CRC
+	V23ExtendedHeaderSize    = 10
+	V23ExtendedHeaderCRCSize = 4
+
+	// Size of the ID3v2.4 extended header without any of the flag data
+	V24ExtendedHeaderSize = 6
+)
+
+// Restrictions are the ID3v2.4 tag restrictions, packed as in the
+// specification
+type Restrictions byte
+
+// Restriction on the number of frames and the tag size, from 0 (128 frames
+// and 1 MB) to 3 (32 frames and 4 KB)
+func (r Restrictions) TagSize() byte {
+	return byte(r>>6) & 0x03
+}
+
+// Whether text is restricted to ISO-8859-1 and UTF-8
+func (r Restrictions) TextEncoding() bool {
+	return isBitSet(byte(r), 5)
+}
+
+// Restriction on the length of text fields, from 0 (no restriction) to 3
+// (30 characters)
+func (r Restrictions) TextFieldSize() byte {
+	return byte(r>>3) & 0x03
+}
+
+// Whether images are restricted to PNG and JPEG
+func (r Restrictions) ImageEncoding() bool {
+	return isBitSet(byte(r), 2)
+}
+
+// Restriction on the image dimensions, from 0 (no restriction) to 3
+// (exactly 64x64 pixels)
+func (r Restrictions) ImageSize() byte {
+	return byte(r) & 0x03
+}
+
+// ExtendedHeader represents the optional extended header of ID3v2.3 and
+// ID3v2.4 tags
+type ExtendedHeader struct {
+	crc             bool
+	checksum        uint32
+	paddingSize     uint32
+	update          bool
+	hasRestrictions bool
+	restrictions    Restrictions
+	owner           *Tag
+}
+
+func NewExtendedHeader() *ExtendedHeader {
+	return &ExtendedHeader{}
+}
+
+// CRC-32 of the tag data as it was read, and whether the tag has one
+func (e ExtendedHeader) CRC() (uint32, bool) {
+	return e.checksum, e.crc
+}
+
+// Set whether a CRC-32 is written with the tag
+func (e *ExtendedHeader) SetCRC(crc bool) {
+	e.change(func() { e.crc = crc })
+}
+
+// Size of the padding as stated by an ID3v2.3 extended header
+func (e ExtendedHeader) PaddingSize() uint32 {
+	return e.paddingSize
+}
+
+// Whether an ID3v2.4 tag is an update of an earlier tag in the file
+func (e ExtendedHeader) Update() bool {
+	return e.update
+}
+
+func (e *ExtendedHeader) SetUpdate(update bool) {
+	e.change(func() { e.update = update })
+}
+
+// Restrictions of an ID3v2.4 tag, and whether the tag is restricted
+func (e ExtendedHeader) Restrictions() (Restrictions, bool) {
+	return e.restrictions, e.hasRestrictions
+}
+
+func (e *ExtendedHeader) SetRestrictions(r Restrictions) {
+	e.change(func() {
+		e.restrictions = r
+		e.hasRestrictions = true
+	})
+}
+
+func (e *ExtendedHeader) ClearRestrictions() {
+	e.change(func() {
+		e.restrictions = 0
+		e.hasRestrictions = false
+	})
+}
+
+// Applies a change to the extended header, resizing the tag it belongs to
+func (e *ExtendedHeader) change(set func()) {
+	if e.owner == nil {
+		set()
+		return
+	}
+
+	before := e.size(e.owner.version)
+	set()
+	e.owner.changeSize(e.size(e.owner.version) - before)
+}
+
+// Size of the extended header when written for a version
+func (e ExtendedHeader) size(version byte) int {
+	if version < 4 {
+		if e.crc {
+			return V23ExtendedHeaderSize + V23ExtendedHeaderCRCSize
+		}
+
+		return V23ExtendedHeaderSize
+	}
+
+	size := V24ExtendedHeaderSize
+	if e.update {
+		size += 1
+	}
+	if e.crc {
+		size += 6
+	}
+	if e.hasRestrictions {
+		size += 2
+	}
+
+	return size
+}
+
+// Forms the extended header for the frames and padding that follow it
+func (e ExtendedHeader) bytes(version byte, frames []byte, padding int) []byte {
+	data := make([]byte, 0, e.size(version))
+
+	if version < 4 {
+		var flags byte
+		if e.crc {
+			flags |= 1 << 7
+		}
+
+		data = append(data, encodedbytes.NormBytes(uint32(e.size(version)-4))...)
+		data = append(data, flags, 0)
+		data = append(data, encodedbytes.NormBytes(uint32(padding))...)
+
+		// The CRC of an ID3v2.3 tag only covers the frames
+		if e.crc {
+			data = append(data, encodedbytes.NormBytes(crc32.ChecksumIEEE(frames))...)
+		}
+
+		return data
+	}
+
+	var flags byte
+	if e.update {
+		flags |= 1 << 6
+	}
+	if e.crc {
+		flags |= 1 << 5
+	}
+	if e.hasRestrictions {
+		flags |= 1 << 4
+	}
+
+	data = append(data, encodedbytes.SynchBytes(uint32(e.size(version)))...)
+	data = append(data, 1, flags)
+
+	if e.update {
+		data = append(data, 0)
+	}
+
+	// The CRC of an ID3v2.4 tag covers the frames and the padding
+	if e.crc {
+		checksum := crc32.Update(crc32.ChecksumIEEE(frames), crc32.IEEETable, make([]byte, padding))
+		data = append(data, 5)
+		data = append(data, synchBytes35(checksum)...)
+	}
+
+	if e.hasRestrictions {
+		data = append(data, 1, byte(e.restrictions))
+	}
+
+	return data
+}
+
+// Reads an extended header from the start of the tag data, returning the
+// extended header and its size
+func readExtendedHeader(data []byte, version byte) (*ExtendedHeader, int, error) {
+	e := new(ExtendedHeader)
+
+	if len(data) < 4 {
+		return nil, 0, extendedHeaderError(truncatedError(len(data), 4))
+	}
+
+	if version < 4 {
+		size, _ := encodedbytes.NormInt(data[:4])
+		size += 4
+
+		if size < V23ExtendedHeaderSize {
+			return nil, 0, extendedHeaderError(fmt.Errorf("size %d is too small", size))
+		}
+
+		if len(data) < int(size) {
+			return nil, 0, extendedHeaderError(truncatedError(len(data), int(size)))
+		}
+
+		e.crc = isBitSet(data[4], 7)
+		e.paddingSize, _ = encodedbytes.NormInt(data[6:10])
+
+		if e.crc {
+			if size < V23ExtendedHeaderSize+V23ExtendedHeaderCRCSize {
+				return nil, 0, extendedHeaderError(fmt.Errorf("size %d is too small for CRC", size))
+			}
+
+			e.checksum, _ = encodedbytes.NormInt(data[10:14])
+		}
+
+		return e, int(size), nil
+	}
+
+	size, err := encodedbytes.SynchInt(data[:4])
+	if err != nil || size < V24ExtendedHeaderSize {
+		return nil, 0, extendedHeaderError(fmt.Errorf("invalid size %v", data[:4]))
+	}
+
+	if len(data) < int(size) {
+		return nil, 0, extendedHeaderError(truncatedError(len(data), int(size)))
+	}
+
+	flags := data[5]
+	rest := data[V24ExtendedHeaderSize:size]
+
+	// Each flag that is set is followed by the length of its data and the
+	// data itself
+	readFlagData := func(bit byte) ([]byte, error) {
+		if !isBitSet(flags, bit) {
+			return nil, nil
+		}
+
+		if len(rest) < 1 || len(rest) < 1+int(rest[0]) {
+			return nil, extendedHeaderError(truncatedError(len(rest), 1))
+		}
+
+		flagData := rest[1 : 1+int(rest[0])]
+		rest = rest[1+int(rest[0]):]
+
+		return flagData, nil
+	}
+
+	if _, err := readFlagData(6); err != nil {
+		return nil, 0, err
+	}
+	e.update = isBitSet(flags, 6)
+
+	crcData, err := readFlagData(5)
+	if err != nil {
+		return nil, 0, err
+	}
+	if e.crc = isBitSet(flags, 5); e.crc {
+		if len(crcData) != 5 {
+			return nil, 0, extendedHeaderError(fmt.Errorf("invalid CRC length %d", len(crcData)))
+		}
+
+		e.checksum = synchInt35(crcData)
+	}
+
+	restrictionData, err := readFlagData(4)
+	if err != nil {
+		return nil, 0, err
+	}
+	if e.hasRestrictions = isBitSet(flags, 4); e.hasRestrictions {
+		if len(restrictionData) != 1 {
+			return nil, 0, extendedHeaderError(fmt.Errorf("invalid restrictions length %d", len(restrictionData)))
+		}
+
+		e.restrictions = Restrictions(restrictionData[0])
+	}
+
+	return e, int(size), nil
+}
+
+// Checks the CRC of the data it covers, which is the frames for ID3v2.3 and
+// the frames and padding for ID3v2.4
+func (e ExtendedHeader) verify(covered []byte) error {
+	if !e.crc {
+		return nil
+	}
+
+	if checksum := crc32.ChecksumIEEE(covered); checksum != e.checksum {
+		return fmt.Errorf("%w: expected %08x, got %08x", ErrCRCMismatch, e.checksum, checksum)
+	}
+
+	return nil
+}
+
+func extendedHeaderError(err error) error {
+	return fmt.Errorf("%w: extended header: %v", ErrInvalidHeader, err)
+}
+
+// The ID3v2.4 CRC is a 32 bit integer stored as a 35 bit synchsafe integer
+func synchBytes35(n uint32) []byte {
+	data := make([]byte, 5)
+	for i := range data {
+		data[len(data)-i-1] = byte(n>>(7*uint(i))) & 0x7f
+	}
+
+	return data
+}
+
+func synchInt35(data []byte) uint32 {
+	var n uint32
+	for _, b := range data {
+		n = n<<7 | uint32(b&0x7f)
+	}
+
+	return n
+}
@@ -0,0 +1,115 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestV23ExtendedHeader(t *testing.T) {
+	tag := NewTag(3)
+	tag.SetTitle("Nice Life")
+
+	extHeader := NewExtendedHeader()
+	extHeader.SetCRC(true)
+	tag.SetExtendedHeader(extHeader)
+
+	data := tag.Bytes()
+	if data[5]&(1<<6) == 0 {
+		t.Error("Bytes did not set extended header flag")
+	}
+
+	parsed, err := ReadTag(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsedHeader := parsed.ExtendedHeader()
+	if parsedHeader == nil {
+		t.Fatal("ReadTag did not read extended header")
+	}
+
+	if _, ok := parsedHeader.CRC(); !ok {
+		t.Error("ReadTag did not read CRC")
+	}
+
+	if padding := parsedHeader.PaddingSize(); padding != uint32(parsed.Padding()) {
+		t.Errorf("extended header padding size %d, expected %d", padding, parsed.Padding())
+	}
+
+	if s := parsed.Title(); s != "Nice Life" {
+		t.Errorf("ReadTag incorrect title, %v", s)
+	}
+
+	if !bytes.Equal(parsed.Bytes(), data) {
+		t.Errorf("Bytes of parsed tag differ:\n%v\n%v", parsed.Bytes(), data)
+	}
+
+	// Corrupt a byte of the title
+	data[len(data)-1] ^= 0xff
+	if _, err := ReadTag(bytes.NewReader(data)); !errors.Is(err, ErrCRCMismatch) {
+		t.Errorf("ReadTag of corrupted tag returned %v, expected CRC mismatch", err)
+	}
+
+	if parsed := ParseTag(bytes.NewReader(data)); parsed == nil || parsed.ExtendedHeader() == nil {
+		t.Error("ParseTag did not ignore CRC mismatch")
+	}
+}
+
+func TestV24ExtendedHeader(t *testing.T) {
+	tag := NewTag(4)
+	tag.SetTitle("Nice Life")
+
+	extHeader := NewExtendedHeader()
+	extHeader.SetCRC(true)
+	extHeader.SetUpdate(true)
+	extHeader.SetRestrictions(0x64)
+	tag.SetExtendedHeader(extHeader)
+
+	data := tag.Bytes()
+
+	parsed, err := ReadTag(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsedHeader := parsed.ExtendedHeader()
+	if parsedHeader == nil {
+		t.Fatal("ReadTag did not read extended header")
+	}
+
+	if _, ok := parsedHeader.CRC(); !ok {
+		t.Error("ReadTag did not read CRC")
+	}
+
+	if !parsedHeader.Update() {
+		t.Error("ReadTag did not read update flag")
+	}
+
+	r, ok := parsedHeader.Restrictions()
+	if !ok {
+		t.Fatal("ReadTag did not read restrictions")
+	}
+
+	if r.TagSize() != 1 || !r.TextEncoding() || r.TextFieldSize() != 0 || !r.ImageEncoding() || r.ImageSize() != 0 {
+		t.Errorf("ReadTag incorrect restrictions, %08b", r)
+	}
+
+	if s := parsed.Title(); s != "Nice Life" {
+		t.Errorf("ReadTag incorrect title, %v", s)
+	}
+
+	// Corrupt a byte of the title
+	data[len(data)-1] ^= 0xff
+	if _, err := ReadTag(bytes.NewReader(data)); !errors.Is(err, ErrCRCMismatch) {
+		t.Errorf("ReadTag of corrupted tag returned %v, expected CRC mismatch", err)
+	}
+
+	parsed.SetExtendedHeader(nil)
+	if data := parsed.Bytes(); data[5]&(1<<6) != 0 {
+		t.Error("Bytes set extended header flag of tag without extended header")
+	}
+}
@@ -4,21 +4,36 @@
 package v2
 
 import (
+	"bytes"
+	"compress/zlib"
 	"errors"
 	"fmt"
 	"github.com/mikkyang/id3-go/encodedbytes"
+	"io"
+	"io/ioutil"
+	"strings"
 )
 
 const (
 	FrameHeaderSize = 10
+
+	// Separator between the values of a multi-value text frame
+	textSeparator = "\x00"
 )
 
+// Frames that are large enough to be worth compressing
+var compressibleFrameIds = map[string]bool{
+	"APIC": true,
+	"GEOB": true,
+	"USLT": true,
+}
+
 // FrameType holds frame id metadata and constructor method
 // A set number of these are created in the version specific files
 type FrameType struct {
 	id          string
 	description string
-	constructor func(FrameHead, []byte) Framer
+	constructor func(FrameHead, []byte) (Framer, error)
 }
 
 // Framer provides a generic interface for frames
@@ -28,6 +43,10 @@ type Framer interface {
 	Size() uint
 	StatusFlags() byte
 	FormatFlags() byte
+	GroupId() (byte, bool)
+	SetGroupId(groupId byte, grouped bool)
+	EncryptionMethod() (byte, bool)
+	SetEncryptionMethod(method byte, encrypted bool) error
 	String() string
 	Bytes() []byte
 	setOwner(*Tag)
@@ -40,6 +59,10 @@ type FrameHead struct {
 	FrameType
 	statusFlags byte
 	formatFlags byte
+	grouped     bool
+	groupId     byte
+	encrypted   bool
+	method      byte
 	size        uint32
 	owner       *Tag
 }
@@ -48,6 +71,212 @@ func (ft FrameType) Id() string {
 	return ft.id
 }
 
+// Looks up the frame type of an ID in a version specific map
+// IDs that are well formed but not in the map get a frame type that keeps the
+// frame as opaque data, so that unknown frames survive a rewrite of the tag
+func lookupFrameType(id string, typeMap map[string]FrameType) (FrameType, bool) {
+	if t, ok := typeMap[id]; ok {
+		return t, true
+	}
+
+	if !isValidFrameId(id) {
+		return FrameType{}, false
+	}
+
+	return FrameType{id: id, description: "Unknown frame", constructor: ReadDataFrame}, true
+}
+
+// frameOptions holds the tag wide settings that affect how frames are read
+// and written
+type frameOptions struct {
+	// Return errors for frames that cannot be parsed as their type
+	strict bool
+	// All frames are unsynchronized, which ID3v2.4 also marks per frame
+	unsynchronization bool
+	// Compress the frames that are worth compressing
+	compression bool
+	// Cipher of an encryption method symbol, without which encrypted frames
+	// are kept as opaque data and cannot be written
+	cipher func(method byte) (FrameCipher, bool)
+}
+
+// Constructs a frame with its frame type
+// When strict is false, frame data that cannot be parsed as its type falls
+// back to an opaque data frame instead of returning an error
+func constructFrame(head FrameHead, data []byte, strict bool) (Framer, error) {
+	f, err := head.constructor(head, data)
+	if err == nil {
+		return f, nil
+	}
+
+	if strict {
+		return nil, err
+	}
+
+	return ParseDataFrame(head, data), nil
+}
+
+// Handles a frame whose format flags describe transformations that could not
+// be undone
+// When strict is false, the frame is kept as opaque data along with its flags
+func untransformedFrame(head FrameHead, data []byte, err error, strict bool) (Framer, error) {
+	if strict {
+		return nil, newFrameError(head.id, err)
+	}
+
+	return ParseDataFrame(head, data), nil
+}
+
+// Compresses frame data with zlib, returning false if compression does not
+// make the data smaller
+func compressFrameData(data []byte) ([]byte, bool) {
+	var b bytes.Buffer
+
+	w := zlib.NewWriter(&b)
+	w.Write(data)
+	w.Close()
+
+	if b.Len() >= len(data) {
+		return nil, false
+	}
+
+	return b.Bytes(), true
+}
+
+// Decompresses zlib frame data, which should decompress to size bytes
+// A negative size means the decompressed size is unknown
+func decompressFrameData(data []byte, size int) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompress: %v", err)
+	}
+	defer r.Close()
+
+	var decompressed []byte
+	if size < 0 {
+		decompressed, err = ioutil.ReadAll(r)
+	} else {
+		// Reading no more than one byte past the expected size keeps bad
+		// data from decompressing to far more than the frame claims
+		decompressed, err = ioutil.ReadAll(io.LimitReader(r, int64(size)+1))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decompress: %v", err)
+	}
+
+	if size >= 0 && len(decompressed) != size {
+		return nil, fmt.Errorf("decompress: %d bytes, expected %d", len(decompressed), size)
+	}
+
+	return decompressed, nil
+}
+
+// Reads a frame header of the given size
+// Returns io.EOF when there are no more frames, either because the reader
+// is empty or because the header is the start of the padding
+func readFrameHeader(reader io.Reader, size int) ([]byte, error) {
+	data := make([]byte, size)
+	if n, err := io.ReadFull(reader, data); err != nil {
+		// Padding can be shorter than a frame header
+		if n == 0 || data[0] == 0 {
+			return nil, io.EOF
+		}
+
+		return nil, newFrameError("", truncatedError(n, size))
+	}
+
+	if data[0] == 0 {
+		return nil, io.EOF
+	}
+
+	return data, nil
+}
+
+// Drops the error of a frame read for the Parse functions, which return nil
+// on failure
+func parsedFrame(f Framer, err error) Framer {
+	if err != nil {
+		return nil
+	}
+
+	return f
+}
+
+// Reads a text encoding byte and checks that it is a known encoding
+func readEncoding(rd *encodedbytes.Reader) (byte, error) {
+	b, err := rd.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if int(b) >= len(encodedbytes.EncodingMap) {
+		return 0, fmt.Errorf("%w: 0x%02x", ErrInvalidEncoding, b)
+	}
+
+	return b, nil
+}
+
+// Encodes text whose values are separated by null characters
+// Each value is encoded on its own, so in UTF-16 every value has its own
+// byte order mark
+func encodeTexts(encoding byte, text string) (string, error) {
+	values := strings.Split(text, textSeparator)
+	for i, value := range values {
+		encoded, err := encodedbytes.Encoders[encoding].ConvertString(value)
+		if err != nil {
+			return "", err
+		}
+
+		values[i] = encoded
+	}
+
+	null := strings.Repeat("\x00", encodedbytes.EncodingNullLengthForIndex(encoding))
+	return strings.Join(values, null), nil
+}
+
+// Difference in size between two texts encoded with encodeTexts
+func encodedTextsDiff(newEncoding byte, newText string, oldEncoding byte, oldText string) (int, error) {
+	newEncoded, err := encodeTexts(newEncoding, newText)
+	if err != nil {
+		return 0, err
+	}
+
+	oldEncoded, err := encodeTexts(oldEncoding, oldText)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(newEncoded) - len(oldEncoded), nil
+}
+
+// Reads the rest of the data as values separated by null characters
+// The data is split on the encoded terminator before it is decoded, so that
+// each UTF-16 value is decoded with its own byte order mark
+func readTexts(rd *encodedbytes.Reader, encoding byte) (string, error) {
+	var values []string
+	for rd.Len() > 0 {
+		value, err := rd.ReadNullTermString(encoding)
+		if err != nil {
+			// The last value is usually not terminated
+			if value, err = rd.ReadRestString(encoding); err != nil {
+				return "", err
+			}
+
+			values = append(values, value)
+			break
+		}
+
+		values = append(values, value)
+
+		// A terminator at the end of the data is kept as an empty value
+		if rd.Len() == 0 {
+			values = append(values, "")
+		}
+	}
+
+	return strings.Join(values, textSeparator), nil
+}
+
 func (h FrameHead) Size() uint {
 	return uint(h.size)
 }
@@ -72,6 +301,48 @@ func (h FrameHead) FormatFlags() byte {
 	return h.formatFlags
 }
 
+// Group identifier of the frame, and whether the frame belongs to a group
+func (h FrameHead) GroupId() (byte, bool) {
+	return h.groupId, h.grouped
+}
+
+// Set the group identifier of the frame, or remove the frame from its group
+// ID3v2.2 frames cannot be grouped
+func (h *FrameHead) SetGroupId(groupId byte, grouped bool) {
+	if !grouped {
+		groupId = 0
+	}
+
+	h.groupId, h.grouped = groupId, grouped
+	h.changeSize(0)
+}
+
+// Encryption method symbol of the frame, and whether the frame is encrypted
+// when it is written
+func (h FrameHead) EncryptionMethod() (byte, bool) {
+	return h.method, h.encrypted
+}
+
+// Set the encryption method symbol the frame is encrypted with when it is
+// written, or write the frame unencrypted
+// The tag of the frame has to register the method with an encryption method
+// registration frame whose owner has a cipher registered with
+// RegisterFrameCipher
+// ID3v2.2 frames cannot be encrypted
+func (h *FrameHead) SetEncryptionMethod(method byte, encrypted bool) error {
+	if !encrypted {
+		method = 0
+	} else if h.owner != nil {
+		if _, ok := h.owner.frameCipher(method); !ok {
+			return fmt.Errorf("encryption: no cipher for method 0x%02x", method)
+		}
+	}
+
+	h.method, h.encrypted = method, encrypted
+	h.changeSize(0)
+	return nil
+}
+
 func (h *FrameHead) setOwner(t *Tag) {
 	h.owner = t
 }
@@ -95,6 +366,10 @@ func ParseDataFrame(head FrameHead, data []byte) Framer {
 	return &DataFrame{head, data}
 }
 
+func ReadDataFrame(head FrameHead, data []byte) (Framer, error) {
+	return ParseDataFrame(head, data), nil
+}
+
 func (f DataFrame) Data() []byte {
 	return f.data
 }
@@ -134,19 +409,27 @@ func NewIdFrame(ft FrameType, ownerId string, id []byte) *IdFrame {
 }
 
 func ParseIdFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadIdFrame(head, data))
+}
+
+func ReadIdFrame(head FrameHead, data []byte) (Framer, error) {
 	var err error
 	f := &IdFrame{FrameHead: head}
 	rd := encodedbytes.NewReader(data)
 
 	if f.ownerIdentifier, err = rd.ReadNullTermString(encodedbytes.NativeEncoding); err != nil {
-		return nil
+		return nil, err
 	}
 
-	if f.identifier, err = rd.ReadRest(); len(f.identifier) > 64 || err != nil {
-		return nil
+	if f.identifier, err = rd.ReadRest(); err != nil {
+		return nil, err
 	}
 
-	return f
+	if len(f.identifier) > 64 {
+		return nil, errors.New("identifier: identifier too long")
+	}
+
+	return f, nil
 }
 
 func (f IdFrame) OwnerIdentifier() string {
@@ -182,7 +465,7 @@ func (f IdFrame) Bytes() []byte {
 	bytes := make([]byte, f.Size())
 	wr := encodedbytes.NewWriter(bytes)
 
-	if err = wr.WriteString(f.ownerIdentifier, encodedbytes.NativeEncoding); err != nil {
+	if err = wr.WriteNullTermString(f.ownerIdentifier, encodedbytes.NativeEncoding); err != nil {
 		return bytes
 	}
 
@@ -222,19 +505,30 @@ func NewTextFrame(ft FrameType, text string) *TextFrame {
 }
 
 func ParseTextFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadTextFrame(head, data))
+}
+
+func ReadTextFrame(head FrameHead, data []byte) (Framer, error) {
 	var err error
 	f := &TextFrame{FrameHead: head}
 	rd := encodedbytes.NewReader(data)
 
-	if f.encoding, err = rd.ReadByte(); err != nil {
-		return nil
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
 	}
 
-	if f.text, err = rd.ReadRestString(f.encoding); err != nil {
-		return nil
+	if f.text, err = readTexts(rd, f.encoding); err != nil {
+		return nil, err
 	}
 
-	return f
+	// Values without a byte order mark get one when they are written
+	text, err := encodeTexts(f.encoding, f.text)
+	if err != nil {
+		return nil, err
+	}
+
+	f.size = uint32(1 + len(text))
+	return f, nil
 }
 
 func (f TextFrame) Encoding() string {
@@ -247,7 +541,7 @@ func (f *TextFrame) SetEncoding(encoding string) error {
 		return errors.New("encoding: invalid encoding")
 	}
 
-	diff, err := encodedbytes.EncodedDiff(i, f.text, f.encoding, f.text)
+	diff, err := encodedTextsDiff(i, f.text, f.encoding, f.text)
 	if err != nil {
 		return err
 	}
@@ -262,7 +556,7 @@ func (f TextFrame) Text() string {
 }
 
 func (f *TextFrame) SetText(text string) error {
-	diff, err := encodedbytes.EncodedDiff(f.encoding, text, f.encoding, f.text)
+	diff, err := encodedTextsDiff(f.encoding, text, f.encoding, f.text)
 	if err != nil {
 		return err
 	}
@@ -272,6 +566,16 @@ func (f *TextFrame) SetText(text string) error {
 	return nil
 }
 
+// All values of the frame
+// ID3v2.4 text frames can hold several values separated by null characters
+func (f TextFrame) Texts() []string {
+	return strings.Split(strings.TrimRight(f.text, textSeparator), textSeparator)
+}
+
+func (f *TextFrame) SetTexts(texts ...string) error {
+	return f.SetText(strings.Join(texts, textSeparator))
+}
+
 func (f TextFrame) String() string {
 	return f.text
 }
@@ -285,7 +589,12 @@ func (f TextFrame) Bytes() []byte {
 		return bytes
 	}
 
-	if err = wr.WriteString(f.text, f.encoding); err != nil {
+	text, err := encodeTexts(f.encoding, f.text)
+	if err != nil {
+		return bytes
+	}
+
+	if n, err := wr.Write([]byte(text)); n < len(text) || err != nil {
 		return bytes
 	}
 
@@ -310,24 +619,52 @@ func NewDescTextFrame(ft FrameType, desc, text string) *DescTextFrame {
 
 // DescTextFrame represents frames that contain encoded text and descriptions
 func ParseDescTextFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadDescTextFrame(head, data))
+}
+
+func ReadDescTextFrame(head FrameHead, data []byte) (Framer, error) {
 	var err error
 	f := new(DescTextFrame)
 	f.FrameHead = head
 	rd := encodedbytes.NewReader(data)
 
-	if f.encoding, err = rd.ReadByte(); err != nil {
-		return nil
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
 	}
 
 	if f.description, err = rd.ReadNullTermString(f.encoding); err != nil {
-		return nil
+		return nil, err
 	}
 
-	if f.text, err = rd.ReadRestString(f.encoding); err != nil {
-		return nil
+	if f.text, err = readTexts(rd, f.encoding); err != nil {
+		return nil, err
 	}
 
-	return f
+	size, err := f.encodedSize()
+	if err != nil {
+		return nil, err
+	}
+
+	f.size = uint32(size)
+	return f, nil
+}
+
+// Size of the frame as it is written
+// Text without a byte order mark gets one when it is written, so frames can
+// grow from the size they were read with
+func (f DescTextFrame) encodedSize() (int, error) {
+	description, err := encodedbytes.Encoders[f.encoding].ConvertString(f.description)
+	if err != nil {
+		return 0, err
+	}
+
+	text, err := encodeTexts(f.encoding, f.text)
+	if err != nil {
+		return 0, err
+	}
+
+	nullLength := encodedbytes.EncodingNullLengthForIndex(f.encoding)
+	return 1 + len(description) + nullLength + len(text), nil
 }
 
 func (f DescTextFrame) Description() string {
@@ -351,7 +688,7 @@ func (f *DescTextFrame) SetEncoding(encoding string) error {
 		return errors.New("encoding: invalid encoding")
 	}
 
-	descDiff, err := encodedbytes.EncodedDiff(i, f.text, f.encoding, f.text)
+	descDiff, err := encodedTextsDiff(i, f.text, f.encoding, f.text)
 	if err != nil {
 		return err
 	}
@@ -383,11 +720,16 @@ func (f DescTextFrame) Bytes() []byte {
 		return bytes
 	}
 
-	if err = wr.WriteString(f.description, f.encoding); err != nil {
+	if err = wr.WriteNullTermString(f.description, f.encoding); err != nil {
 		return bytes
 	}
 
-	if err = wr.WriteString(f.text, f.encoding); err != nil {
+	text, err := encodeTexts(f.encoding, f.text)
+	if err != nil {
+		return bytes
+	}
+
+	if n, err := wr.Write([]byte(text)); n < len(text) || err != nil {
 		return bytes
 	}
 
@@ -411,28 +753,39 @@ func NewUnsynchTextFrame(ft FrameType, desc, text string) *UnsynchTextFrame {
 }
 
 func ParseUnsynchTextFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadUnsynchTextFrame(head, data))
+}
+
+func ReadUnsynchTextFrame(head FrameHead, data []byte) (Framer, error) {
 	var err error
 	f := new(UnsynchTextFrame)
 	f.FrameHead = head
 	rd := encodedbytes.NewReader(data)
 
-	if f.encoding, err = rd.ReadByte(); err != nil {
-		return nil
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
 	}
 
 	if f.language, err = rd.ReadNumBytesString(3); err != nil {
-		return nil
+		return nil, err
 	}
 
 	if f.description, err = rd.ReadNullTermString(f.encoding); err != nil {
-		return nil
+		return nil, err
 	}
 
-	if f.text, err = rd.ReadRestString(f.encoding); err != nil {
-		return nil
+	if f.text, err = readTexts(rd, f.encoding); err != nil {
+		return nil, err
 	}
 
-	return f
+	size, err := f.encodedSize()
+	if err != nil {
+		return nil, err
+	}
+
+	// The language comes before the description
+	f.size = uint32(3 + size)
+	return f, nil
 }
 
 func (f UnsynchTextFrame) Language() string {
@@ -470,7 +823,12 @@ func (f UnsynchTextFrame) Bytes() []byte {
 		return bytes
 	}
 
-	if err = wr.WriteString(f.text, f.encoding); err != nil {
+	text, err := encodeTexts(f.encoding, f.text)
+	if err != nil {
+		return bytes
+	}
+
+	if n, err := wr.Write([]byte(text)); n < len(text) || err != nil {
 		return bytes
 	}
 
@@ -486,33 +844,80 @@ type ImageFrame struct {
 	description string
 }
 
+// Creates a picture frame
+// The description is encoded as ISO-8859-1 when possible, and as UTF-16
+// otherwise, both of which are valid in every version
+func NewImageFrame(ft FrameType, mimeType string, pictureType PictureType, description string, data []byte) *ImageFrame {
+	var encoding byte
+	encoded, err := encodedbytes.Encoders[encoding].ConvertString(description)
+	if err != nil {
+		encoding = encodedbytes.IndexForEncoding("UTF-16")
+		encoded, _ = encodedbytes.Encoders[encoding].ConvertString(description)
+	}
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(1 + len(mimeType) + 1 + 1 + len(encoded) + encodedbytes.EncodingNullLengthForIndex(encoding) + len(data)),
+	}
+
+	return &ImageFrame{
+		DataFrame:   DataFrame{head, data},
+		encoding:    encoding,
+		mimeType:    mimeType,
+		pictureType: byte(pictureType),
+		description: description,
+	}
+}
+
 func ParseImageFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadImageFrame(head, data))
+}
+
+func ReadImageFrame(head FrameHead, data []byte) (Framer, error) {
 	var err error
 	f := new(ImageFrame)
 	f.FrameHead = head
 	rd := encodedbytes.NewReader(data)
 
-	if f.encoding, err = rd.ReadByte(); err != nil {
-		return nil
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
 	}
 
 	if f.mimeType, err = rd.ReadNullTermString(encodedbytes.NativeEncoding); err != nil {
-		return nil
+		return nil, err
 	}
 
 	if f.pictureType, err = rd.ReadByte(); err != nil {
-		return nil
+		return nil, err
 	}
 
 	if f.description, err = rd.ReadNullTermString(f.encoding); err != nil {
-		return nil
+		return nil, err
 	}
 
 	if f.data, err = rd.ReadRest(); err != nil {
-		return nil
+		return nil, err
 	}
 
-	return f
+	size, err := f.descriptionSize()
+	if err != nil {
+		return nil, err
+	}
+
+	f.size = uint32(1 + len(f.mimeType) + 1 + 1 + size + len(f.data))
+	return f, nil
+}
+
+// Size of the description and its terminator as they are written
+// A description without a byte order mark gets one when it is written, and
+// one with only a byte order mark is written without it
+func (f ImageFrame) descriptionSize() (int, error) {
+	description, err := encodedbytes.Encoders[f.encoding].ConvertString(f.description)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(description) + encodedbytes.EncodingNullLengthForIndex(f.encoding), nil
 }
 
 func (f ImageFrame) Encoding() string {
@@ -530,7 +935,10 @@ func (f *ImageFrame) SetEncoding(encoding string) error {
 		return err
 	}
 
-	f.changeSize(diff)
+	newNullLength := encodedbytes.EncodingNullLengthForIndex(i)
+	oldNullLength := encodedbytes.EncodingNullLengthForIndex(f.encoding)
+
+	f.changeSize(diff + newNullLength - oldNullLength)
 	f.encoding = i
 	return nil
 }
@@ -540,16 +948,34 @@ func (f ImageFrame) MIMEType() string {
 }
 
 func (f *ImageFrame) SetMIMEType(mimeType string) {
-	diff := len(mimeType) - len(f.mimeType)
-	if mimeType[len(mimeType)-1] != 0 {
-		nullTermBytes := append([]byte(mimeType), 0x00)
-		f.mimeType = string(nullTermBytes)
-		diff += 1
-	} else {
-		f.mimeType = mimeType
+	mimeType = strings.TrimRight(mimeType, "\x00")
+
+	f.changeSize(len(mimeType) - len(f.mimeType))
+	f.mimeType = mimeType
+}
+
+func (f ImageFrame) PictureType() PictureType {
+	return PictureType(f.pictureType)
+}
+
+func (f *ImageFrame) SetPictureType(pictureType PictureType) {
+	f.pictureType = byte(pictureType)
+	f.changeSize(0)
+}
+
+func (f ImageFrame) Description() string {
+	return f.description
+}
+
+func (f *ImageFrame) SetDescription(description string) error {
+	diff, err := encodedbytes.EncodedDiff(f.encoding, description, f.encoding, f.description)
+	if err != nil {
+		return err
 	}
 
 	f.changeSize(diff)
+	f.description = description
+	return nil
 }
 
 func (f ImageFrame) String() string {
@@ -565,7 +991,103 @@ func (f ImageFrame) Bytes() []byte {
 		return bytes
 	}
 
-	if err = wr.WriteString(f.mimeType, encodedbytes.NativeEncoding); err != nil {
+	if err = wr.WriteNullTermString(f.mimeType, encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteByte(f.pictureType); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.description, f.encoding); err != nil {
+		return bytes
+	}
+
+	if n, err := wr.Write(f.data); n < len(f.data) || err != nil {
+		return bytes
+	}
+
+	return bytes
+}
+
+// PictureFrame represents ID3v2.2 picture frames, which have a three
+// character image format instead of a MIME type
+type PictureFrame struct {
+	ImageFrame
+}
+
+// Creates an ID3v2.2 picture frame
+// The MIME type is stored as the image format it corresponds to
+func NewPictureFrame(ft FrameType, mimeType string, pictureType PictureType, description string, data []byte) *PictureFrame {
+	f := NewImageFrame(ft, mimeType, pictureType, description, data)
+	f.mimeType = pictureFormatMIMEType(pictureFormat(mimeType))
+	f.size = uint32(int(f.size) - len(mimeType) - 1 + pictureFormatSize)
+
+	return &PictureFrame{*f}
+}
+
+func ParsePictureFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadPictureFrame(head, data))
+}
+
+func ReadPictureFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := new(PictureFrame)
+	f.FrameHead = head
+	rd := encodedbytes.NewReader(data)
+
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
+	}
+
+	format, err := rd.ReadNumBytesString(pictureFormatSize)
+	if err != nil {
+		return nil, err
+	}
+	f.mimeType = pictureFormatMIMEType(format)
+
+	if f.pictureType, err = rd.ReadByte(); err != nil {
+		return nil, err
+	}
+
+	if f.description, err = rd.ReadNullTermString(f.encoding); err != nil {
+		return nil, err
+	}
+
+	if f.data, err = rd.ReadRest(); err != nil {
+		return nil, err
+	}
+
+	size, err := f.descriptionSize()
+	if err != nil {
+		return nil, err
+	}
+
+	f.size = uint32(1 + pictureFormatSize + 1 + size + len(f.data))
+	return f, nil
+}
+
+// Three character image format, such as "JPG" or "PNG"
+func (f PictureFrame) Format() string {
+	return pictureFormat(f.mimeType)
+}
+
+// Sets the MIME type, which is stored as the image format it corresponds to
+func (f *PictureFrame) SetMIMEType(mimeType string) {
+	f.mimeType = pictureFormatMIMEType(pictureFormat(mimeType))
+	f.changeSize(0)
+}
+
+func (f PictureFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteByte(f.encoding); err != nil {
+		return bytes
+	}
+
+	if _, err = wr.Write([]byte(f.Format())); err != nil {
 		return bytes
 	}
 
@@ -573,7 +1095,7 @@ func (f ImageFrame) Bytes() []byte {
 		return bytes
 	}
 
-	if err = wr.WriteString(f.description, f.encoding); err != nil {
+	if err = wr.WriteNullTermString(f.description, f.encoding); err != nil {
 		return bytes
 	}
 
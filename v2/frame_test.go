@@ -1,6 +1,7 @@
 package v2
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -28,3 +29,23 @@ func TestUnsynchTextFrameSetEncoding(t *testing.T) {
 		t.Errorf("expected size to decrease to %d, but it was %d", size-1, newSize)
 	}
 }
+
+func TestUnsynchTextFrameWithoutByteOrderMarks(t *testing.T) {
+	// UTF-16 without byte order marks is read as big endian
+	data := []byte("\x01eng\x27\x13\x00\x00\x27\x13")
+	frame, err := ReadUnsynchTextFrame(FrameHead{FrameType: V23FrameTypeMap["COMM"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*UnsynchTextFrame)
+	if f.Description() != "\u2713" || f.Text() != "\u2713" {
+		t.Errorf("ReadUnsynchTextFrame incorrect frame, %v", f)
+	}
+
+	// The byte order marks written with the text are part of the size
+	expected := []byte("\x01eng\xff\xfe\x13\x27\x00\x00\xff\xfe\x13\x27")
+	if b := f.Bytes(); int(f.Size()) != len(expected) || !bytes.Equal(b, expected) {
+		t.Errorf("Bytes produces %v, expected %v", b, expected)
+	}
+}
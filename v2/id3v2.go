@@ -4,108 +4,350 @@
 package v2
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"github.com/mikkyang/id3-go/encodedbytes"
 	"io"
 	"os"
+	"sync"
 )
 
 const (
 	HeaderSize = 10
+	FooterSize = 10
 )
 
 // Tag represents an ID3v2 tag
 type Tag struct {
 	*Header
-	frames                map[string][]Framer
+	frames                []Framer
+	extHeader             *ExtendedHeader
 	padding               uint
 	commonMap             map[string]FrameType
 	frameHeaderSize       int
-	frameConstructor      func(io.Reader) Framer
-	frameBytesConstructor func(Framer) []byte
+	frameConstructor      func(io.Reader, frameOptions) (Framer, error)
+	frameBytesConstructor func(Framer, frameOptions) ([]byte, error)
+	frameCompression      bool
+	canonicalOrder        bool
+	paddingPolicy         *PaddingPolicy
+	encoded               *frameCache
 	dirty                 bool
 }
 
+// frameCache holds the frames of a tag as they were last encoded
+// Size queries reuse them, so that ciphers and compression only run again
+// once the tag has been modified
+type frameCache struct {
+	sync.Mutex
+	valid   bool
+	ciphers uint64
+	frames  []byte
+	err     error
+}
+
+// PaddingPolicy decides the padding of a tag as it changes size
+// A tag keeps its size while its padding is between Min and Max, and is
+// resized to have Target bytes of padding otherwise
+// The zero value strips all padding
+type PaddingPolicy struct {
+	Min, Target, Max int
+}
+
 // Creates a new tag
 func NewTag(version byte) *Tag {
 	header := &Header{version: version}
 
 	t := &Tag{
-		Header: header,
-		frames: make(map[string][]Framer),
-		dirty:  false,
+		Header:  header,
+		encoded: new(frameCache),
+		dirty:   false,
 	}
 
-	switch t.version {
+	t.setVersion(version)
+
+	return t
+}
+
+// Sets the version specific frame handling of the tag
+func (t *Tag) setVersion(version byte) {
+	t.version = version
+
+	switch version {
 	case 2:
 		t.commonMap = V22CommonFrame
-		t.frameConstructor = ParseV22Frame
+		t.frameConstructor = readV22Frame
 		t.frameHeaderSize = V22FrameHeaderSize
-		t.frameBytesConstructor = V22Bytes
+		t.frameBytesConstructor = v22Bytes
 	case 3:
 		t.commonMap = V23CommonFrame
-		t.frameConstructor = ParseV23Frame
+		t.frameConstructor = readV23Frame
+		t.frameHeaderSize = FrameHeaderSize
+		t.frameBytesConstructor = v23Bytes
+	case 4:
+		t.commonMap = V24CommonFrame
+		t.frameConstructor = readV24Frame
 		t.frameHeaderSize = FrameHeaderSize
-		t.frameBytesConstructor = V23Bytes
+		t.frameBytesConstructor = v24Bytes
 	default:
 		t.commonMap = V23CommonFrame
-		t.frameConstructor = ParseV23Frame
+		t.frameConstructor = readV23Frame
 		t.frameHeaderSize = FrameHeaderSize
-		t.frameBytesConstructor = V23Bytes
+		t.frameBytesConstructor = v23Bytes
 	}
-
-	return t
 }
 
 // Parses a new tag
 func ParseTag(readSeeker io.ReadSeeker) *Tag {
-	header := ParseHeader(readSeeker)
+	t, _ := readTag(readSeeker, false)
+	return t
+}
 
-	if header == nil {
-		return nil
+// Reads a new tag, returning an error describing what could not be read
+// ErrNoTag is returned if the reader does not start with a tag
+func ReadTag(readSeeker io.ReadSeeker) (*Tag, error) {
+	return readTag(readSeeker, true)
+}
+
+// Reads a tag and its frames
+// When strict is false, a tag is returned up to the first frame that cannot
+// be read and the rest of the tag is treated as padding
+func readTag(readSeeker io.ReadSeeker, strict bool) (*Tag, error) {
+	header, err := ReadHeader(readSeeker)
+	if err != nil {
+		return nil, err
 	}
 
 	t := NewTag(header.version)
 	t.Header = header
 
-	var frame Framer
-	size := int(t.size)
-	for size > 0 {
-		frame = t.frameConstructor(readSeeker)
+	data := make([]byte, t.size)
+	if n, err := io.ReadFull(readSeeker, data); err != nil && strict {
+		return nil, truncatedError(n, len(data))
+	}
 
-		if frame == nil {
+	opts := frameOptions{strict: strict}
+	if t.unsynchronization {
+		// Before ID3v2.4, unsynchronization is applied to the tag as a whole
+		if t.version < 4 {
+			data = encodedbytes.Resynchronize(data)
+		} else {
+			opts.unsynchronization = true
+		}
+	}
+	start := 0
+	if t.extendedHeader {
+		extHeader, n, err := readExtendedHeader(data, t.version)
+		if err != nil {
+			if strict {
+				return nil, err
+			}
+
+			// Without the extended header the frames cannot be found
+			n = len(data)
+			t.extendedHeader = false
+			t.flags &^= 1 << 6
+		} else {
+			t.extHeader = extHeader
+			extHeader.owner = t
+		}
+
+		start = n
+	}
+
+	// The CRC covers the frames, and from ID3v2.4 the padding as well
+	// It is checked before the frames are read, so that corrupted frames are
+	// reported as a CRC mismatch rather than as frames that cannot be read
+	if t.extHeader != nil && strict {
+		covered := data[start:]
+		if t.version < 4 {
+			covered = covered[:v23FramesEnd(covered)]
+		}
+
+		if err := t.extHeader.verify(covered); err != nil {
+			return nil, err
+		}
+	}
+
+	reader := bytes.NewReader(data[start:])
+	end := start
+
+	for reader.Len() > 0 {
+		frame, err := t.frameConstructor(reader, opts)
+
+		if err == io.EOF {
 			break
 		}
 
-		id := frame.Id()
-		t.frames[id] = append(t.frames[id], frame)
+		if err != nil {
+			if !strict {
+				// Skip the rest of the tag
+				break
+			}
+
+			if frameErr, ok := err.(*FrameError); ok {
+				frameErr.Offset += int64(HeaderSize + end)
+			}
+			return nil, err
+		}
+
+		t.frames = append(t.frames, frame)
 		frame.setOwner(t)
 
-		size -= t.frameHeaderSize + int(frame.Size())
+		end = len(data) - reader.Len()
 	}
 
-	t.padding = uint(size)
-	if _, err := readSeeker.Seek(int64(HeaderSize+t.Size()), os.SEEK_SET); err != nil {
-		return nil
+	if err := t.decryptFrames(strict); err != nil {
+		return nil, err
 	}
 
-	return t
+	// Whatever is left after the frames is padding
+	t.padding = uint(len(data) - end)
+	if _, err := readSeeker.Seek(int64(HeaderSize+t.Header.Size()+t.footerSize()), os.SEEK_SET); err != nil {
+		return nil, err
+	}
+
+	return t, nil
 }
 
-// Real size of the tag
+// Real size of the tag, excluding padding, as it is written
+// Unsynchronization and compression change the size of the frames, so the
+// frames are encoded to find it, once after each change to the tag
 func (t Tag) RealSize() int {
-	size := uint(t.size) - t.padding
-	return int(size)
+	data, _, _ := t.body(false)
+	return len(data)
+}
+
+// Size of the tag, excluding the header and footer
+// Space freed by frames becomes padding, and the tag grows as frames are
+// added once the padding has been used up
+// With a padding policy, the tag is resized to the target padding when the
+// padding falls outside of the policy
+// Like RealSize, this encodes the frames when the tag has changed
+func (t Tag) Size() int {
+	return t.paddedSize(t.RealSize())
+}
+
+func (t Tag) paddedSize(realSize int) int {
+	// Tags with a footer are not allowed to have padding
+	if t.footer {
+		return realSize
+	}
+
+	if realSize > int(t.size) {
+		if t.paddingPolicy != nil {
+			return realSize + t.paddingPolicy.Target
+		}
+
+		return realSize
+	}
+
+	return int(t.size)
 }
 
 func (t *Tag) changeSize(diff int) {
-	if d := int(t.padding) - diff; d < 0 {
+	// Tags with a footer are not allowed to have padding
+	if t.footer {
+		t.size = uint32(int(t.size) + diff)
+		t.modified()
+		return
+	}
+
+	padding := int(t.padding) - diff
+	if p := t.paddingPolicy; p != nil {
+		if padding < p.Min || padding > p.Max {
+			padding = p.Target
+		}
+	} else if padding < 0 {
+		padding = 0
+	}
+
+	t.size = uint32(int(t.size) - int(t.padding) + diff + padding)
+	t.padding = uint(padding)
+
+	t.modified()
+}
+
+// Padding policy of the tag, or nil if the tag has none
+func (t Tag) PaddingPolicy() *PaddingPolicy {
+	return t.paddingPolicy
+}
+
+// Set the padding policy of the tag, or remove it with nil
+// The policy applies to the current padding, and again whenever the tag
+// changes size
+func (t *Tag) SetPaddingPolicy(p *PaddingPolicy) error {
+	if p != nil && (p.Min < 0 || p.Min > p.Target || p.Target > p.Max) {
+		return fmt.Errorf("padding policy: %d, %d and %d are not in order", p.Min, p.Target, p.Max)
+	}
+
+	size, dirty := t.size, t.dirty
+	t.paddingPolicy = p
+	t.changeSize(0)
+	t.dirty = dirty || t.size != size
+
+	return nil
+}
+
+// Set whether the tag is followed by a footer
+// Only ID3v2.4 tags can have a footer and a tag with a footer has no padding
+func (t *Tag) SetFooter(footer bool) {
+	if t.version < 4 || t.footer == footer {
+		return
+	}
+
+	t.footer = footer
+	if footer {
+		t.flags |= 1 << 4
+		t.size -= uint32(t.padding)
 		t.padding = 0
-		t.size += uint32(-d)
 	} else {
-		t.padding = uint(d)
+		t.flags &^= 1 << 4
+	}
+
+	t.modified()
+}
+
+// Set whether the tag is written with unsynchronization, which keeps
+// players that do not know about ID3v2 from mistaking tag data for audio
+func (t *Tag) SetUnsynchronization(unsynchronization bool) {
+	if t.unsynchronization == unsynchronization {
+		return
+	}
+
+	t.unsynchronization = unsynchronization
+	if unsynchronization {
+		t.flags |= 1 << 7
+	} else {
+		t.flags &^= 1 << 7
+	}
+
+	t.modified()
+}
+
+// Whether large frames are written with zlib compression
+func (t Tag) FrameCompression() bool {
+	return t.frameCompression
+}
+
+// Set whether large frames, such as pictures, objects and lyrics, are written
+// with zlib compression when it makes them smaller
+// ID3v2.2 frames cannot be compressed
+func (t *Tag) SetFrameCompression(compression bool) {
+	if t.frameCompression == compression {
+		return
 	}
 
+	t.frameCompression = compression
+	t.modified()
+}
+
+// Marks the tag as modified, so that its frames are encoded again
+func (t *Tag) modified() {
+	t.encoded.Lock()
+	t.encoded.valid = false
+	t.encoded.Unlock()
+
 	t.dirty = true
 }
 
@@ -114,62 +356,184 @@ func (t Tag) Dirty() bool {
 	return t.dirty
 }
 
+// Encodes the tag, leaving out frames that cannot be written, such as
+// encrypted frames whose cipher fails
 func (t Tag) Bytes() []byte {
-	data := make([]byte, t.Size())
+	data, _ := t.bytes(false)
+	return data
+}
+
+// Encodes the tag, returning an error for frames that cannot be written
+func (t Tag) MarshalBinary() ([]byte, error) {
+	return t.bytes(true)
+}
+
+// When strict is false, frames that cannot be written are left out
+func (t Tag) bytes(strict bool) ([]byte, error) {
+	body, padding, err := t.body(strict)
+	if err != nil {
+		return nil, err
+	}
+
+	header := *t.Header
+	header.size = uint32(len(body) + padding)
+
+	data := make([]byte, 0, HeaderSize+header.Size()+header.footerSize())
+	data = append(data, header.Bytes()...)
+	data = append(data, body...)
+	data = append(data, make([]byte, padding)...)
+
+	if t.footer {
+		data = append(data, header.footerBytes()...)
+	}
+
+	return data, nil
+}
+
+// Extended header and frames as they are written after the header, and the
+// amount of padding that follows them
+// When strict is false, frames that cannot be written are left out
+func (t Tag) body(strict bool) ([]byte, int, error) {
+	frames, err := t.encodeFrames()
+	if err != nil && strict {
+		return nil, 0, err
+	}
+
+	// Before ID3v2.4, unsynchronization is applied to the tag as a whole
+	tagUnsynchronization := t.unsynchronization && t.version < 4
+
+	data := frames
+	if tagUnsynchronization {
+		data = encodedbytes.Unsynchronize(frames)
+	}
 
-	index := 0
-	for _, v := range t.frames {
-		for _, f := range v {
-			size := t.frameHeaderSize + int(f.Size())
-			copy(data[index:index+size], t.frameBytesConstructor(f))
+	realSize := len(data)
+	if t.extHeader != nil {
+		realSize += t.extHeader.size(t.version)
+	}
+	padding := t.paddedSize(realSize) - realSize
+
+	if t.extHeader == nil {
+		return data, padding, nil
+	}
+
+	data = append(t.extHeader.bytes(t.version, frames, padding), frames...)
+	if tagUnsynchronization {
+		data = encodedbytes.Unsynchronize(data)
+
+		// Unsynchronization of the extended header takes up padding
+		if padding -= len(data) - realSize; padding < 0 {
+			padding = 0
+		}
+	}
+
+	return data, padding, nil
+}
+
+// Frames as they are written, leaving out frames that cannot be written, and
+// the error of the first of them
+func (t Tag) encodeFrames() ([]byte, error) {
+	t.encoded.Lock()
+	defer t.encoded.Unlock()
+
+	// Registering ciphers changes which frames can be written
+	ciphers := frameCiphersGeneration()
+	if t.encoded.valid && t.encoded.ciphers == ciphers {
+		return t.encoded.frames, t.encoded.err
+	}
+
+	opts := frameOptions{
+		unsynchronization: t.unsynchronization,
+		compression:       t.frameCompression,
+		cipher:            t.frameCipher,
+	}
+
+	order := t.frames
+	if t.canonicalOrder {
+		order = canonicalFrameOrder(t.frames)
+	}
 
-			index += size
+	var frames []byte
+	var firstErr error
+	for _, f := range order {
+		data, err := t.frameBytesConstructor(f, opts)
+		if err != nil && firstErr == nil {
+			firstErr = err
 		}
+
+		frames = append(frames, data...)
 	}
 
-	return append(t.Header.Bytes(), data...)
+	t.encoded.valid, t.encoded.ciphers = true, ciphers
+	t.encoded.frames, t.encoded.err = frames, firstErr
+
+	return frames, firstErr
 }
 
 // The amount of padding in the tag
+// Like RealSize, this encodes the frames when the tag has changed
 func (t Tag) Padding() uint {
-	return t.padding
+	_, padding, _ := t.body(false)
+	return uint(padding)
 }
 
-// All frames
-func (t Tag) AllFrames() []Framer {
-	// Most of the time each ID will only have one frame
-	m := len(t.frames)
-	frames := make([]Framer, m)
-
-	i := 0
-	for _, frameSlice := range t.frames {
-		if i >= m {
-			frames = append(frames, frameSlice...)
-		}
+// Extended header of the tag, or nil if the tag has none
+func (t Tag) ExtendedHeader() *ExtendedHeader {
+	return t.extHeader
+}
 
-		n := copy(frames[i:], frameSlice)
-		i += n
-		if n < len(frameSlice) {
-			frames = append(frames, frameSlice[n:]...)
-		}
+// Set the extended header of the tag, or remove it with nil
+// ID3v2.2 tags have no extended header
+func (t *Tag) SetExtendedHeader(e *ExtendedHeader) {
+	if t.version < 3 || t.extHeader == e {
+		return
+	}
+
+	diff := 0
+	if t.extHeader != nil {
+		t.extHeader.owner = nil
+		diff -= t.extHeader.size(t.version)
 	}
 
+	t.extHeader = e
+	t.extendedHeader = e != nil
+	if e != nil {
+		e.owner = t
+		diff += e.size(t.version)
+		t.flags |= 1 << 6
+	} else {
+		t.flags &^= 1 << 6
+	}
+
+	t.changeSize(diff)
+}
+
+// All frames, in the order they are written
+func (t Tag) AllFrames() []Framer {
+	frames := make([]Framer, len(t.frames))
+	copy(frames, t.frames)
+
 	return frames
 }
 
 // All frames with specified ID
 func (t Tag) Frames(id string) []Framer {
-	if frames, ok := t.frames[id]; ok && frames != nil {
-		return frames
+	frames := []Framer{}
+	for _, frame := range t.frames {
+		if frame.Id() == id {
+			frames = append(frames, frame)
+		}
 	}
 
-	return []Framer{}
+	return frames
 }
 
 // First frame with specified ID
 func (t Tag) Frame(id string) Framer {
-	if frames := t.Frames(id); len(frames) != 0 {
-		return frames[0]
+	for _, frame := range t.frames {
+		if frame.Id() == id {
+			return frame
+		}
 	}
 
 	return nil
@@ -177,34 +541,106 @@ func (t Tag) Frame(id string) Framer {
 
 // Delete and return all frames with specified ID
 func (t *Tag) DeleteFrames(id string) []Framer {
-	frames := t.Frames(id)
-	if frames == nil {
-		return nil
-	}
+	var frames []Framer
+	kept := t.frames[:0]
 
 	diff := 0
-	for _, frame := range frames {
+	for _, frame := range t.frames {
+		if frame.Id() != id {
+			kept = append(kept, frame)
+			continue
+		}
+
 		frame.setOwner(nil)
+		frames = append(frames, frame)
 		diff += t.frameHeaderSize + int(frame.Size())
 	}
-	t.changeSize(-diff)
 
-	delete(t.frames, id)
+	// Clear the references left behind the kept frames
+	for i := len(kept); i < len(t.frames); i++ {
+		t.frames[i] = nil
+	}
+
+	t.frames = kept
+	t.changeSize(-diff)
 
 	return frames
 }
 
-// Add frames
+// Add frames after the existing frames
 func (t *Tag) AddFrames(frames ...Framer) {
 	for _, frame := range frames {
 		t.changeSize(t.frameHeaderSize + int(frame.Size()))
 
-		id := frame.Id()
-		t.frames[id] = append(t.frames[id], frame)
+		t.frames = append(t.frames, frame)
 		frame.setOwner(t)
 	}
 }
 
+// Insert a frame at an index in the frame order, from 0 to the number of
+// frames
+func (t *Tag) InsertFrameAt(index int, frame Framer) error {
+	if index < 0 || index > len(t.frames) {
+		return fmt.Errorf("insert frame: index %d out of range", index)
+	}
+
+	t.changeSize(t.frameHeaderSize + int(frame.Size()))
+
+	t.frames = append(t.frames, nil)
+	copy(t.frames[index+1:], t.frames[index:])
+	t.frames[index] = frame
+	frame.setOwner(t)
+
+	return nil
+}
+
+// Move a frame of the tag to an index in the frame order
+func (t *Tag) MoveFrame(frame Framer, index int) error {
+	from := -1
+	for i, f := range t.frames {
+		if f == frame {
+			from = i
+			break
+		}
+	}
+
+	if from < 0 {
+		return errors.New("move frame: frame is not in the tag")
+	}
+
+	if index < 0 || index >= len(t.frames) {
+		return fmt.Errorf("move frame: index %d out of range", index)
+	}
+
+	if from < index {
+		copy(t.frames[from:index], t.frames[from+1:index+1])
+	} else {
+		copy(t.frames[index+1:from+1], t.frames[index:from])
+	}
+	t.frames[index] = frame
+
+	t.modified()
+	return nil
+}
+
+// Whether frames are written in canonical order
+func (t Tag) CanonicalOrder() bool {
+	return t.canonicalOrder
+}
+
+// Set whether frames are written in canonical order instead of the order
+// they were read or added in
+// The canonical order starts with the common text frames and ends with the
+// pictures, so that tags with the same frames have the same bytes
+func (t *Tag) SetCanonicalOrder(canonical bool) {
+	if t.canonicalOrder == canonical {
+		return
+	}
+
+	t.canonicalOrder = canonical
+	t.modified()
+}
+
 func (t Tag) Title() string {
 	return t.textFrameText(t.commonMap["Title"])
 }
@@ -289,15 +725,30 @@ func (t *Tag) setTextFrameText(ft FrameType, text string) {
 }
 
 func ParseHeader(reader io.Reader) *Header {
+	header, err := ReadHeader(reader)
+	if err != nil {
+		return nil
+	}
+
+	return header
+}
+
+// Reads a tag header, returning ErrNoTag if the reader does not start with a
+// tag and ErrInvalidHeader if the header is malformed
+func ReadHeader(reader io.Reader) (*Header, error) {
 	data := make([]byte, HeaderSize)
 	n, err := io.ReadFull(reader, data)
-	if n < HeaderSize || err != nil || string(data[:3]) != "ID3" {
-		return nil
+	if n < 3 || string(data[:3]) != "ID3" {
+		return nil, ErrNoTag
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, truncatedError(n, HeaderSize))
 	}
 
 	size, err := encodedbytes.SynchInt(data[6:])
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("%w: size %v is not synchsafe", ErrInvalidHeader, data[6:])
 	}
 
 	header := &Header{
@@ -315,9 +766,16 @@ func ParseHeader(reader io.Reader) *Header {
 		header.unsynchronization = isBitSet(header.flags, 7)
 		header.extendedHeader = isBitSet(header.flags, 6)
 		header.experimental = isBitSet(header.flags, 5)
+	case 4:
+		header.unsynchronization = isBitSet(header.flags, 7)
+		header.extendedHeader = isBitSet(header.flags, 6)
+		header.experimental = isBitSet(header.flags, 5)
+		header.footer = isBitSet(header.flags, 4)
+	default:
+		return nil, fmt.Errorf("%w: unsupported version 2.%d", ErrInvalidHeader, header.version)
 	}
 
-	return header
+	return header, nil
 }
 
 // Header represents the data of the header of the entire tag
@@ -328,6 +786,7 @@ type Header struct {
 	compression       bool
 	experimental      bool
 	extendedHeader    bool
+	footer            bool
 	size              uint32
 }
 
@@ -348,3 +807,28 @@ func (h Header) Bytes() []byte {
 
 	return data
 }
+
+func (h Header) footerBytes() []byte {
+	data := h.Bytes()
+	copy(data, "3DI")
+
+	return data
+}
+
+func (h Header) footerSize() int {
+	if h.footer {
+		return FooterSize
+	}
+
+	return 0
+}
+
+// Whether the tag is unsynchronized
+func (h Header) Unsynchronization() bool {
+	return h.unsynchronization
+}
+
+// Whether the tag is followed by a footer, which is only used in ID3v2.4
+func (h Header) Footer() bool {
+	return h.footer
+}
@@ -15,97 +15,116 @@ const (
 var (
 	// Common frame IDs
 	V22CommonFrame = map[string]FrameType{
-		"Title":    V22FrameTypeMap["TT2"],
-		"Artist":   V22FrameTypeMap["TP1"],
-		"Album":    V22FrameTypeMap["TAL"],
-		"Year":     V22FrameTypeMap["TYE"],
-		"Genre":    V22FrameTypeMap["TCO"],
-		"Comments": V22FrameTypeMap["COM"],
+		"Title":          V22FrameTypeMap["TT2"],
+		"Artist":         V22FrameTypeMap["TP1"],
+		"Album":          V22FrameTypeMap["TAL"],
+		"Year":           V22FrameTypeMap["TYE"],
+		"Genre":          V22FrameTypeMap["TCO"],
+		"Comments":       V22FrameTypeMap["COM"],
+		"Picture":        V22FrameTypeMap["PIC"],
+		"Popularimeter":  V22FrameTypeMap["POP"],
+		"PlayCounter":    V22FrameTypeMap["CNT"],
+		"ArtistURL":      V22FrameTypeMap["WAR"],
+		"SourceURL":      V22FrameTypeMap["WAS"],
+		"Object":         V22FrameTypeMap["GEO"],
+		"UserText":       V22FrameTypeMap["TXX"],
+		"InvolvedPeople": V22FrameTypeMap["IPL"],
+		"UniqueID":       V22FrameTypeMap["UFI"],
 	}
 
 	// V22FrameTypeMap specifies the frame IDs and constructors allowed in ID3v2.2
 	V22FrameTypeMap = map[string]FrameType{
-		"BUF": FrameType{id: "BUF", description: "Recommended buffer size", constructor: ParseDataFrame},
-		"CNT": FrameType{id: "CNT", description: "Play counter", constructor: ParseDataFrame},
-		"COM": FrameType{id: "COM", description: "Comments", constructor: ParseUnsynchTextFrame},
-		"CRA": FrameType{id: "CRA", description: "Audio encryption", constructor: ParseDataFrame},
-		"CRM": FrameType{id: "CRM", description: "Encrypted meta frame", constructor: ParseDataFrame},
-		"ETC": FrameType{id: "ETC", description: "Event timing codes", constructor: ParseDataFrame},
-		"EQU": FrameType{id: "EQU", description: "Equalization", constructor: ParseDataFrame},
-		"GEO": FrameType{id: "GEO", description: "General encapsulated object", constructor: ParseDataFrame},
-		"IPL": FrameType{id: "IPL", description: "Involved people list", constructor: ParseDataFrame},
-		"LNK": FrameType{id: "LNK", description: "Linked information", constructor: ParseDataFrame},
-		"MCI": FrameType{id: "MCI", description: "Music CD Identifier", constructor: ParseDataFrame},
-		"MLL": FrameType{id: "MLL", description: "MPEG location lookup table", constructor: ParseDataFrame},
-		"PIC": FrameType{id: "PIC", description: "Attached picture", constructor: ParseDataFrame},
-		"POP": FrameType{id: "POP", description: "Popularimeter", constructor: ParseDataFrame},
-		"REV": FrameType{id: "REV", description: "Reverb", constructor: ParseDataFrame},
-		"RVA": FrameType{id: "RVA", description: "Relative volume adjustment", constructor: ParseDataFrame},
-		"SLT": FrameType{id: "SLT", description: "Synchronized lyric/text", constructor: ParseDataFrame},
-		"STC": FrameType{id: "STC", description: "Synced tempo codes", constructor: ParseDataFrame},
-		"TAL": FrameType{id: "TAL", description: "Album/Movie/Show title", constructor: ParseTextFrame},
-		"TBP": FrameType{id: "TBP", description: "BPM (Beats Per Minute)", constructor: ParseTextFrame},
-		"TCM": FrameType{id: "TCM", description: "Composer", constructor: ParseTextFrame},
-		"TCO": FrameType{id: "TCO", description: "Content type", constructor: ParseTextFrame},
-		"TCR": FrameType{id: "TCR", description: "Copyright message", constructor: ParseTextFrame},
-		"TDA": FrameType{id: "TDA", description: "Date", constructor: ParseTextFrame},
-		"TDY": FrameType{id: "TDY", description: "Playlist delay", constructor: ParseTextFrame},
-		"TEN": FrameType{id: "TEN", description: "Encoded by", constructor: ParseTextFrame},
-		"TFT": FrameType{id: "TFT", description: "File type", constructor: ParseTextFrame},
-		"TIM": FrameType{id: "TIM", description: "Time", constructor: ParseTextFrame},
-		"TKE": FrameType{id: "TKE", description: "Initial key", constructor: ParseTextFrame},
-		"TLA": FrameType{id: "TLA", description: "Language(s)", constructor: ParseTextFrame},
-		"TLE": FrameType{id: "TLE", description: "Length", constructor: ParseTextFrame},
-		"TMT": FrameType{id: "TMT", description: "Media type", constructor: ParseTextFrame},
-		"TOA": FrameType{id: "TOA", description: "Original artist(s)/performer(s)", constructor: ParseTextFrame},
-		"TOF": FrameType{id: "TOF", description: "Original filename", constructor: ParseTextFrame},
-		"TOL": FrameType{id: "TOL", description: "Original Lyricist(s)/text writer(s)", constructor: ParseTextFrame},
-		"TOR": FrameType{id: "TOR", description: "Original release year", constructor: ParseTextFrame},
-		"TOT": FrameType{id: "TOT", description: "Original album/Movie/Show title", constructor: ParseTextFrame},
-		"TP1": FrameType{id: "TP1", description: "Lead artist(s)/Lead performer(s)/Soloist(s)/Performing group", constructor: ParseTextFrame},
-		"TP2": FrameType{id: "TP2", description: "Band/Orchestra/Accompaniment", constructor: ParseTextFrame},
-		"TP3": FrameType{id: "TP3", description: "Conductor/Performer refinement", constructor: ParseTextFrame},
-		"TP4": FrameType{id: "TP4", description: "Interpreted, remixed, or otherwise modified by", constructor: ParseTextFrame},
-		"TPA": FrameType{id: "TPA", description: "Part of a set", constructor: ParseTextFrame},
-		"TPB": FrameType{id: "TPB", description: "Publisher", constructor: ParseTextFrame},
-		"TRC": FrameType{id: "TRC", description: "ISRC (International Standard Recording Code)", constructor: ParseTextFrame},
-		"TRD": FrameType{id: "TRD", description: "Recording dates", constructor: ParseTextFrame},
-		"TRK": FrameType{id: "TRK", description: "Track number/Position in set", constructor: ParseTextFrame},
-		"TSI": FrameType{id: "TSI", description: "Size", constructor: ParseTextFrame},
-		"TSS": FrameType{id: "TSS", description: "Software/hardware and settings used for encoding", constructor: ParseTextFrame},
-		"TT1": FrameType{id: "TT1", description: "Content group description", constructor: ParseTextFrame},
-		"TT2": FrameType{id: "TT2", description: "Title/Songname/Content description", constructor: ParseTextFrame},
-		"TT3": FrameType{id: "TT3", description: "Subtitle/Description refinement", constructor: ParseTextFrame},
-		"TXT": FrameType{id: "TXT", description: "Lyricist/text writer", constructor: ParseTextFrame},
-		"TXX": FrameType{id: "TXX", description: "User defined text information frame", constructor: ParseDescTextFrame},
-		"TYE": FrameType{id: "TYE", description: "Year", constructor: ParseTextFrame},
-		"UFI": FrameType{id: "UFI", description: "Unique file identifier", constructor: ParseDataFrame},
-		"ULT": FrameType{id: "ULT", description: "Unsychronized lyric/text transcription", constructor: ParseDataFrame},
-		"WAF": FrameType{id: "WAF", description: "Official audio file webpage", constructor: ParseDataFrame},
-		"WAR": FrameType{id: "WAR", description: "Official artist/performer webpage", constructor: ParseDataFrame},
-		"WAS": FrameType{id: "WAS", description: "Official audio source webpage", constructor: ParseDataFrame},
-		"WCM": FrameType{id: "WCM", description: "Commercial information", constructor: ParseDataFrame},
-		"WCP": FrameType{id: "WCP", description: "Copyright/Legal information", constructor: ParseDataFrame},
-		"WPB": FrameType{id: "WPB", description: "Publishers official webpage", constructor: ParseDataFrame},
-		"WXX": FrameType{id: "WXX", description: "User defined URL link frame", constructor: ParseDataFrame},
+		"BUF": FrameType{id: "BUF", description: "Recommended buffer size", constructor: ReadDataFrame},
+		"CNT": FrameType{id: "CNT", description: "Play counter", constructor: ReadPlayCounterFrame},
+		"COM": FrameType{id: "COM", description: "Comments", constructor: ReadUnsynchTextFrame},
+		"CRA": FrameType{id: "CRA", description: "Audio encryption", constructor: ReadAudioEncryptionFrame},
+		"CRM": FrameType{id: "CRM", description: "Encrypted meta frame", constructor: ReadDataFrame},
+		"ETC": FrameType{id: "ETC", description: "Event timing codes", constructor: ReadEventTimingFrame},
+		"EQU": FrameType{id: "EQU", description: "Equalization", constructor: ReadDataFrame},
+		"GEO": FrameType{id: "GEO", description: "General encapsulated object", constructor: ReadGEOBFrame},
+		"IPL": FrameType{id: "IPL", description: "Involved people list", constructor: ReadInvolvedPeopleFrame},
+		"LNK": FrameType{id: "LNK", description: "Linked information", constructor: ReadDataFrame},
+		"MCI": FrameType{id: "MCI", description: "Music CD Identifier", constructor: ReadMCDIFrame},
+		"MLL": FrameType{id: "MLL", description: "MPEG location lookup table", constructor: ReadMLLTFrame},
+		"PIC": FrameType{id: "PIC", description: "Attached picture", constructor: ReadPictureFrame},
+		"POP": FrameType{id: "POP", description: "Popularimeter", constructor: ReadPopularimeterFrame},
+		"REV": FrameType{id: "REV", description: "Reverb", constructor: ReadDataFrame},
+		"RVA": FrameType{id: "RVA", description: "Relative volume adjustment", constructor: ReadRelativeVolumeFrame},
+		"SLT": FrameType{id: "SLT", description: "Synchronized lyric/text", constructor: ReadSyncLyricsFrame},
+		"STC": FrameType{id: "STC", description: "Synced tempo codes", constructor: ReadSyncTempoFrame},
+		"TAL": FrameType{id: "TAL", description: "Album/Movie/Show title", constructor: ReadTextFrame},
+		"TBP": FrameType{id: "TBP", description: "BPM (Beats Per Minute)", constructor: ReadTextFrame},
+		"TCM": FrameType{id: "TCM", description: "Composer", constructor: ReadTextFrame},
+		"TCO": FrameType{id: "TCO", description: "Content type", constructor: ReadTextFrame},
+		"TCR": FrameType{id: "TCR", description: "Copyright message", constructor: ReadTextFrame},
+		"TDA": FrameType{id: "TDA", description: "Date", constructor: ReadTextFrame},
+		"TDY": FrameType{id: "TDY", description: "Playlist delay", constructor: ReadTextFrame},
+		"TEN": FrameType{id: "TEN", description: "Encoded by", constructor: ReadTextFrame},
+		"TFT": FrameType{id: "TFT", description: "File type", constructor: ReadTextFrame},
+		"TIM": FrameType{id: "TIM", description: "Time", constructor: ReadTextFrame},
+		"TKE": FrameType{id: "TKE", description: "Initial key", constructor: ReadTextFrame},
+		"TLA": FrameType{id: "TLA", description: "Language(s)", constructor: ReadTextFrame},
+		"TLE": FrameType{id: "TLE", description: "Length", constructor: ReadTextFrame},
+		"TMT": FrameType{id: "TMT", description: "Media type", constructor: ReadTextFrame},
+		"TOA": FrameType{id: "TOA", description: "Original artist(s)/performer(s)", constructor: ReadTextFrame},
+		"TOF": FrameType{id: "TOF", description: "Original filename", constructor: ReadTextFrame},
+		"TOL": FrameType{id: "TOL", description: "Original Lyricist(s)/text writer(s)", constructor: ReadTextFrame},
+		"TOR": FrameType{id: "TOR", description: "Original release year", constructor: ReadTextFrame},
+		"TOT": FrameType{id: "TOT", description: "Original album/Movie/Show title", constructor: ReadTextFrame},
+		"TP1": FrameType{id: "TP1", description: "Lead artist(s)/Lead performer(s)/Soloist(s)/Performing group", constructor: ReadTextFrame},
+		"TP2": FrameType{id: "TP2", description: "Band/Orchestra/Accompaniment", constructor: ReadTextFrame},
+		"TP3": FrameType{id: "TP3", description: "Conductor/Performer refinement", constructor: ReadTextFrame},
+		"TP4": FrameType{id: "TP4", description: "Interpreted, remixed, or otherwise modified by", constructor: ReadTextFrame},
+		"TPA": FrameType{id: "TPA", description: "Part of a set", constructor: ReadTextFrame},
+		"TPB": FrameType{id: "TPB", description: "Publisher", constructor: ReadTextFrame},
+		"TRC": FrameType{id: "TRC", description: "ISRC (International Standard Recording Code)", constructor: ReadTextFrame},
+		"TRD": FrameType{id: "TRD", description: "Recording dates", constructor: ReadTextFrame},
+		"TRK": FrameType{id: "TRK", description: "Track number/Position in set", constructor: ReadTextFrame},
+		"TSI": FrameType{id: "TSI", description: "Size", constructor: ReadTextFrame},
+		"TSS": FrameType{id: "TSS", description: "Software/hardware and settings used for encoding", constructor: ReadTextFrame},
+		"TT1": FrameType{id: "TT1", description: "Content group description", constructor: ReadTextFrame},
+		"TT2": FrameType{id: "TT2", description: "Title/Songname/Content description", constructor: ReadTextFrame},
+		"TT3": FrameType{id: "TT3", description: "Subtitle/Description refinement", constructor: ReadTextFrame},
+		"TXT": FrameType{id: "TXT", description: "Lyricist/text writer", constructor: ReadTextFrame},
+		"TXX": FrameType{id: "TXX", description: "User defined text information frame", constructor: ReadDescTextFrame},
+		"TYE": FrameType{id: "TYE", description: "Year", constructor: ReadTextFrame},
+		"UFI": FrameType{id: "UFI", description: "Unique file identifier", constructor: ReadIdFrame},
+		"ULT": FrameType{id: "ULT", description: "Unsychronized lyric/text transcription", constructor: ReadUnsynchTextFrame},
+		"WAF": FrameType{id: "WAF", description: "Official audio file webpage", constructor: ReadURLFrame},
+		"WAR": FrameType{id: "WAR", description: "Official artist/performer webpage", constructor: ReadURLFrame},
+		"WAS": FrameType{id: "WAS", description: "Official audio source webpage", constructor: ReadURLFrame},
+		"WCM": FrameType{id: "WCM", description: "Commercial information", constructor: ReadURLFrame},
+		"WCP": FrameType{id: "WCP", description: "Copyright/Legal information", constructor: ReadURLFrame},
+		"WPB": FrameType{id: "WPB", description: "Publishers official webpage", constructor: ReadURLFrame},
+		"WXX": FrameType{id: "WXX", description: "User defined URL link frame", constructor: ReadUserURLFrame},
 	}
 )
 
 func ParseV22Frame(reader io.Reader) Framer {
-	data := make([]byte, V22FrameHeaderSize)
-	if n, err := io.ReadFull(reader, data); n < V22FrameHeaderSize || err != nil {
-		return nil
+	return parsedFrame(readV22Frame(reader, frameOptions{}))
+}
+
+// Reads a frame, returning an error describing why the frame could not be
+// read, or io.EOF if the reader is at the end of the frames
+func ReadV22Frame(reader io.Reader) (Framer, error) {
+	return readV22Frame(reader, frameOptions{strict: true})
+}
+
+func readV22Frame(reader io.Reader, opts frameOptions) (Framer, error) {
+	data, err := readFrameHeader(reader, V22FrameHeaderSize)
+	if err != nil {
+		return nil, err
 	}
 
 	id := string(data[:3])
-	t, ok := V22FrameTypeMap[id]
+	t, ok := lookupFrameType(id, V22FrameTypeMap)
 	if !ok {
-		return nil
+		return nil, newFrameError(id, ErrInvalidFrameId)
 	}
 
 	size, err := encodedbytes.NormInt(data[3:6])
 	if err != nil {
-		return nil
+		return nil, newFrameError(id, err)
 	}
 
 	h := FrameHead{
@@ -114,18 +133,28 @@ func ParseV22Frame(reader io.Reader) Framer {
 	}
 
 	frameData := make([]byte, size)
-	if n, err := io.ReadFull(reader, frameData); n < int(size) || err != nil {
-		return nil
+	if n, err := io.ReadFull(reader, frameData); err != nil {
+		return nil, newFrameError(id, truncatedError(n, int(size)))
 	}
 
-	return t.constructor(h, frameData)
+	f, err := constructFrame(h, frameData, opts.strict)
+	if err != nil {
+		return nil, newFrameError(id, err)
+	}
+
+	return f, nil
 }
 
 func V22Bytes(f Framer) []byte {
+	data, _ := v22Bytes(f, frameOptions{})
+	return data
+}
+
+func v22Bytes(f Framer, opts frameOptions) ([]byte, error) {
 	headBytes := make([]byte, 0, V22FrameHeaderSize)
 
 	headBytes = append(headBytes, f.Id()...)
 	headBytes = append(headBytes, encodedbytes.NormBytes(uint32(f.Size()))[1:]...)
 
-	return append(headBytes, f.Bytes()...)
+	return append(headBytes, f.Bytes()...), nil
 }
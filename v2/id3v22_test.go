@@ -30,3 +30,63 @@ func TestV22Frame(t *testing.T) {
 		t.Errorf("V23Bytes produces different byte slice, expected %v not %v", textData, b)
 	}
 }
+
+func TestV22PictureFrame(t *testing.T) {
+	data := []byte{80, 73, 67, 0, 0, 13, 0, 'P', 'N', 'G', 3, 'C', 'o', 'v', 'e', 'r', 0, 0x89, 'P'}
+	frame := ParseV22Frame(bytes.NewReader(data))
+	picture, ok := frame.(*PictureFrame)
+	if !ok {
+		t.Fatalf("ParseV22Frame on picture data returns wrong type")
+	}
+
+	if s := picture.MIMEType(); s != "image/png" {
+		t.Errorf("ParseV22Frame incorrect MIME type, %v", s)
+	}
+
+	if p := picture.PictureType(); p != PictureCoverFront {
+		t.Errorf("ParseV22Frame incorrect picture type, %v", p)
+	}
+
+	if s := picture.Description(); s != "Cover" {
+		t.Errorf("ParseV22Frame incorrect description, %v", s)
+	}
+
+	if b := V22Bytes(frame); !bytes.Equal(data, b) {
+		t.Errorf("V22Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	picture.SetMIMEType("image/jpeg")
+	if s := picture.Format(); s != "JPG" {
+		t.Errorf("SetMIMEType incorrect format, %v", s)
+	}
+
+	tag := NewTag(2)
+	if err := tag.SetPicture(PictureCoverBack, "", "Back", []byte("\xff\xd8\xff")); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := ParseTag(bytes.NewReader(tag.Bytes()))
+	back, ok := parsed.Picture(PictureCoverBack).(*PictureFrame)
+	if !ok {
+		t.Fatalf("SetPicture did not add picture frame, %v", parsed.Frame("PIC"))
+	}
+
+	if s := back.Format(); s != "JPG" {
+		t.Errorf("SetPicture incorrect format, %v", s)
+	}
+}
+
+func TestV22PictureFrameByteOrderMarkDescription(t *testing.T) {
+	// A UTF-16 description with only a byte order mark is written without it
+	data := []byte("\x01JPG\x03\xff\xfe\x00\x00\xff\xd8\xff\xd9")
+	frame, err := ReadPictureFrame(FrameHead{FrameType: V22FrameTypeMap["PIC"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	picture := frame.(*PictureFrame)
+	expected := []byte("\x01JPG\x03\x00\x00\xff\xd8\xff\xd9")
+	if b := picture.Bytes(); int(picture.Size()) != len(expected) || !bytes.Equal(b, expected) {
+		t.Errorf("Bytes produces %v, expected %v", b, expected)
+	}
+}
@@ -8,131 +8,162 @@ import (
 	"io"
 )
 
+const (
+	// ID3v2.3 frame format flag bits
+	V23FlagCompression = 7
+	V23FlagEncryption  = 6
+	V23FlagGrouping    = 5
+
+	// Size of the decompressed size that precedes compressed frame data
+	decompressedSizeSize = 4
+)
+
 var (
 	// Common frame IDs
 	V23CommonFrame = map[string]FrameType{
-		"Title":    V23FrameTypeMap["TIT2"],
-		"Artist":   V23FrameTypeMap["TPE1"],
-		"Album":    V23FrameTypeMap["TALB"],
-		"Year":     V23FrameTypeMap["TYER"],
-		"Genre":    V23FrameTypeMap["TCON"],
-		"Comments": V23FrameTypeMap["COMM"],
+		"Title":          V23FrameTypeMap["TIT2"],
+		"Artist":         V23FrameTypeMap["TPE1"],
+		"Album":          V23FrameTypeMap["TALB"],
+		"Year":           V23FrameTypeMap["TYER"],
+		"Genre":          V23FrameTypeMap["TCON"],
+		"Comments":       V23FrameTypeMap["COMM"],
+		"Picture":        V23FrameTypeMap["APIC"],
+		"Popularimeter":  V23FrameTypeMap["POPM"],
+		"PlayCounter":    V23FrameTypeMap["PCNT"],
+		"ArtistURL":      V23FrameTypeMap["WOAR"],
+		"SourceURL":      V23FrameTypeMap["WOAS"],
+		"Object":         V23FrameTypeMap["GEOB"],
+		"Private":        V23FrameTypeMap["PRIV"],
+		"UserText":       V23FrameTypeMap["TXXX"],
+		"InvolvedPeople": V23FrameTypeMap["IPLS"],
+		"UniqueID":       V23FrameTypeMap["UFID"],
 	}
 
 	// V23DeprecatedTypeMap contains deprecated frame IDs from ID3v2.2
 	V23DeprecatedTypeMap = map[string]string{
-		"BUF": "RBUF", "COM": "COMM", "CRA": "AENC", "EQU": "EQUA",
-		"ETC": "ETCO", "GEO": "GEOB", "MCI": "MCDI", "MLL": "MLLT",
-		"PIC": "APIC", "POP": "POPM", "REV": "RVRB", "RVA": "RVAD",
-		"SLT": "SYLT", "STC": "SYTC", "TAL": "TALB", "TBP": "TBPM",
-		"TCM": "TCOM", "TCO": "TCON", "TCR": "TCOP", "TDA": "TDAT",
-		"TDY": "TDLY", "TEN": "TENC", "TFT": "TFLT", "TIM": "TIME",
-		"TKE": "TKEY", "TLA": "TLAN", "TLE": "TLEN", "TMT": "TMED",
-		"TOA": "TOPE", "TOF": "TOFN", "TOL": "TOLY", "TOR": "TORY",
-		"TOT": "TOAL", "TP1": "TPE1", "TP2": "TPE2", "TP3": "TPE3",
-		"TP4": "TPE4", "TPA": "TPOS", "TPB": "TPUB", "TRC": "TSRC",
-		"TRD": "TRDA", "TRK": "TRCK", "TSI": "TSIZ", "TSS": "TSSE",
-		"TT1": "TIT1", "TT2": "TIT2", "TT3": "TIT3", "TXT": "TEXT",
-		"TXX": "TXXX", "TYE": "TYER", "UFI": "UFID", "ULT": "USLT",
-		"WAF": "WOAF", "WAR": "WOAR", "WAS": "WOAS", "WCM": "WCOM",
-		"WCP": "WCOP", "WPB": "WPB", "WXX": "WXXX",
+		"BUF": "RBUF", "CNT": "PCNT", "COM": "COMM", "CRA": "AENC",
+		"EQU": "EQUA", "ETC": "ETCO", "GEO": "GEOB", "IPL": "IPLS",
+		"LNK": "LINK", "MCI": "MCDI", "MLL": "MLLT", "PIC": "APIC",
+		"POP": "POPM", "REV": "RVRB", "RVA": "RVAD", "SLT": "SYLT",
+		"STC": "SYTC", "TAL": "TALB", "TBP": "TBPM", "TCM": "TCOM",
+		"TCO": "TCON", "TCR": "TCOP", "TDA": "TDAT", "TDY": "TDLY",
+		"TEN": "TENC", "TFT": "TFLT", "TIM": "TIME", "TKE": "TKEY",
+		"TLA": "TLAN", "TLE": "TLEN", "TMT": "TMED", "TOA": "TOPE",
+		"TOF": "TOFN", "TOL": "TOLY", "TOR": "TORY", "TOT": "TOAL",
+		"TP1": "TPE1", "TP2": "TPE2", "TP3": "TPE3", "TP4": "TPE4",
+		"TPA": "TPOS", "TPB": "TPUB", "TRC": "TSRC", "TRD": "TRDA",
+		"TRK": "TRCK", "TSI": "TSIZ", "TSS": "TSSE", "TT1": "TIT1",
+		"TT2": "TIT2", "TT3": "TIT3", "TXT": "TEXT", "TXX": "TXXX",
+		"TYE": "TYER", "UFI": "UFID", "ULT": "USLT", "WAF": "WOAF",
+		"WAR": "WOAR", "WAS": "WOAS", "WCM": "WCOM", "WCP": "WCOP",
+		"WPB": "WPUB", "WXX": "WXXX",
 	}
 
 	// V23FrameTypeMap specifies the frame IDs and constructors allowed in ID3v2.3
 	V23FrameTypeMap = map[string]FrameType{
-		"AENC": FrameType{id: "AENC", description: "Audio encryption", constructor: ParseDataFrame},
-		"APIC": FrameType{id: "APIC", description: "Attached picture", constructor: ParseImageFrame},
-		"COMM": FrameType{id: "COMM", description: "Comments", constructor: ParseUnsynchTextFrame},
-		"COMR": FrameType{id: "COMR", description: "Commercial frame", constructor: ParseDataFrame},
-		"ENCR": FrameType{id: "ENCR", description: "Encryption method registration", constructor: ParseDataFrame},
-		"EQUA": FrameType{id: "EQUA", description: "Equalization", constructor: ParseDataFrame},
-		"ETCO": FrameType{id: "ETCO", description: "Event timing codes", constructor: ParseDataFrame},
-		"GEOB": FrameType{id: "GEOB", description: "General encapsulated object", constructor: ParseDataFrame},
-		"GRID": FrameType{id: "GRID", description: "Group identification registration", constructor: ParseDataFrame},
-		"IPLS": FrameType{id: "IPLS", description: "Involved people list", constructor: ParseDataFrame},
-		"LINK": FrameType{id: "LINK", description: "Linked information", constructor: ParseDataFrame},
-		"MCDI": FrameType{id: "MCDI", description: "Music CD identifier", constructor: ParseDataFrame},
-		"MLLT": FrameType{id: "MLLT", description: "MPEG location lookup table", constructor: ParseDataFrame},
-		"OWNE": FrameType{id: "OWNE", description: "Ownership frame", constructor: ParseDataFrame},
-		"PRIV": FrameType{id: "PRIV", description: "Private frame", constructor: ParseDataFrame},
-		"PCNT": FrameType{id: "PCNT", description: "Play counter", constructor: ParseDataFrame},
-		"POPM": FrameType{id: "POPM", description: "Popularimeter", constructor: ParseDataFrame},
-		"POSS": FrameType{id: "POSS", description: "Position synchronisation frame", constructor: ParseDataFrame},
-		"RBUF": FrameType{id: "RBUF", description: "Recommended buffer size", constructor: ParseDataFrame},
-		"RVAD": FrameType{id: "RVAD", description: "Relative volume adjustment", constructor: ParseDataFrame},
-		"RVRB": FrameType{id: "RVRB", description: "Reverb", constructor: ParseDataFrame},
-		"SYLT": FrameType{id: "SYLT", description: "Synchronized lyric/text", constructor: ParseDataFrame},
-		"SYTC": FrameType{id: "SYTC", description: "Synchronized tempo codes", constructor: ParseDataFrame},
-		"TALB": FrameType{id: "TALB", description: "Album/Movie/Show title", constructor: ParseTextFrame},
-		"TBPM": FrameType{id: "TBPM", description: "BPM (beats per minute)", constructor: ParseTextFrame},
-		"TCOM": FrameType{id: "TCOM", description: "Composer", constructor: ParseTextFrame},
-		"TCON": FrameType{id: "TCON", description: "Content type", constructor: ParseTextFrame},
-		"TCOP": FrameType{id: "TCOP", description: "Copyright message", constructor: ParseTextFrame},
-		"TDAT": FrameType{id: "TDAT", description: "Date", constructor: ParseTextFrame},
-		"TDLY": FrameType{id: "TDLY", description: "Playlist delay", constructor: ParseTextFrame},
-		"TENC": FrameType{id: "TENC", description: "Encoded by", constructor: ParseTextFrame},
-		"TEXT": FrameType{id: "TEXT", description: "Lyricist/Text writer", constructor: ParseTextFrame},
-		"TFLT": FrameType{id: "TFLT", description: "File type", constructor: ParseTextFrame},
-		"TIME": FrameType{id: "TIME", description: "Time", constructor: ParseTextFrame},
-		"TIT1": FrameType{id: "TIT1", description: "Content group description", constructor: ParseTextFrame},
-		"TIT2": FrameType{id: "TIT2", description: "Title/songname/content description", constructor: ParseTextFrame},
-		"TIT3": FrameType{id: "TIT3", description: "Subtitle/Description refinement", constructor: ParseTextFrame},
-		"TKEY": FrameType{id: "TKEY", description: "Initial key", constructor: ParseTextFrame},
-		"TLAN": FrameType{id: "TLAN", description: "Language(s)", constructor: ParseTextFrame},
-		"TLEN": FrameType{id: "TLEN", description: "Length", constructor: ParseTextFrame},
-		"TMED": FrameType{id: "TMED", description: "Media type", constructor: ParseTextFrame},
-		"TOAL": FrameType{id: "TOAL", description: "Original album/movie/show title", constructor: ParseTextFrame},
-		"TOFN": FrameType{id: "TOFN", description: "Original filename", constructor: ParseTextFrame},
-		"TOLY": FrameType{id: "TOLY", description: "Original lyricist(s)/text writer(s)", constructor: ParseTextFrame},
-		"TOPE": FrameType{id: "TOPE", description: "Original artist(s)/performer(s)", constructor: ParseTextFrame},
-		"TORY": FrameType{id: "TORY", description: "Original release year", constructor: ParseTextFrame},
-		"TOWN": FrameType{id: "TOWN", description: "File owner/licensee", constructor: ParseTextFrame},
-		"TPE1": FrameType{id: "TPE1", description: "Lead performer(s)/Soloist(s)", constructor: ParseTextFrame},
-		"TPE2": FrameType{id: "TPE2", description: "Band/orchestra/accompaniment", constructor: ParseTextFrame},
-		"TPE3": FrameType{id: "TPE3", description: "Conductor/performer refinement", constructor: ParseTextFrame},
-		"TPE4": FrameType{id: "TPE4", description: "Interpreted, remixed, or otherwise modified by", constructor: ParseTextFrame},
-		"TPOS": FrameType{id: "TPOS", description: "Part of a set", constructor: ParseTextFrame},
-		"TPUB": FrameType{id: "TPUB", description: "Publisher", constructor: ParseTextFrame},
-		"TRCK": FrameType{id: "TRCK", description: "Track number/Position in set", constructor: ParseTextFrame},
-		"TRDA": FrameType{id: "TRDA", description: "Recording dates", constructor: ParseTextFrame},
-		"TRSN": FrameType{id: "TRSN", description: "Internet radio station name", constructor: ParseTextFrame},
-		"TRSO": FrameType{id: "TRSO", description: "Internet radio station owner", constructor: ParseTextFrame},
-		"TSIZ": FrameType{id: "TSIZ", description: "Size", constructor: ParseTextFrame},
-		"TSRC": FrameType{id: "TSRC", description: "ISRC (international standard recording code)", constructor: ParseTextFrame},
-		"TSSE": FrameType{id: "TSSE", description: "Software/Hardware and settings used for encoding", constructor: ParseTextFrame},
-		"TYER": FrameType{id: "TYER", description: "Year", constructor: ParseTextFrame},
-		"TXXX": FrameType{id: "TXXX", description: "User defined text information frame", constructor: ParseDescTextFrame},
-		"UFID": FrameType{id: "UFID", description: "Unique file identifier", constructor: ParseIdFrame},
-		"USER": FrameType{id: "USER", description: "Terms of use", constructor: ParseDataFrame},
-		"TCMP": FrameType{id: "TCMP", description: "Part of a compilation (iTunes extension)", constructor: ParseTextFrame},
-		"USLT": FrameType{id: "USLT", description: "Unsychronized lyric/text transcription", constructor: ParseUnsynchTextFrame},
-		"WCOM": FrameType{id: "WCOM", description: "Commercial information", constructor: ParseDataFrame},
-		"WCOP": FrameType{id: "WCOP", description: "Copyright/Legal information", constructor: ParseDataFrame},
-		"WOAF": FrameType{id: "WOAF", description: "Official audio file webpage", constructor: ParseDataFrame},
-		"WOAR": FrameType{id: "WOAR", description: "Official artist/performer webpage", constructor: ParseDataFrame},
-		"WOAS": FrameType{id: "WOAS", description: "Official audio source webpage", constructor: ParseDataFrame},
-		"WORS": FrameType{id: "WORS", description: "Official internet radio station homepage", constructor: ParseDataFrame},
-		"WPAY": FrameType{id: "WPAY", description: "Payment", constructor: ParseDataFrame},
-		"WPUB": FrameType{id: "WPUB", description: "Publishers official webpage", constructor: ParseDataFrame},
-		"WXXX": FrameType{id: "WXXX", description: "User defined URL link frame", constructor: ParseDataFrame},
+		"AENC": FrameType{id: "AENC", description: "Audio encryption", constructor: ReadAudioEncryptionFrame},
+		"APIC": FrameType{id: "APIC", description: "Attached picture", constructor: ReadImageFrame},
+		"COMM": FrameType{id: "COMM", description: "Comments", constructor: ReadUnsynchTextFrame},
+		"COMR": FrameType{id: "COMR", description: "Commercial frame", constructor: ReadCommercialFrame},
+		"ENCR": FrameType{id: "ENCR", description: "Encryption method registration", constructor: ReadEncryptionMethodFrame},
+		"EQUA": FrameType{id: "EQUA", description: "Equalization", constructor: ReadDataFrame},
+		"ETCO": FrameType{id: "ETCO", description: "Event timing codes", constructor: ReadEventTimingFrame},
+		"GEOB": FrameType{id: "GEOB", description: "General encapsulated object", constructor: ReadGEOBFrame},
+		"GRID": FrameType{id: "GRID", description: "Group identification registration", constructor: ReadGroupIdFrame},
+		"IPLS": FrameType{id: "IPLS", description: "Involved people list", constructor: ReadInvolvedPeopleFrame},
+		"LINK": FrameType{id: "LINK", description: "Linked information", constructor: ReadDataFrame},
+		"MCDI": FrameType{id: "MCDI", description: "Music CD identifier", constructor: ReadMCDIFrame},
+		"MLLT": FrameType{id: "MLLT", description: "MPEG location lookup table", constructor: ReadMLLTFrame},
+		"OWNE": FrameType{id: "OWNE", description: "Ownership frame", constructor: ReadOwnershipFrame},
+		"PRIV": FrameType{id: "PRIV", description: "Private frame", constructor: ReadPrivateFrame},
+		"PCNT": FrameType{id: "PCNT", description: "Play counter", constructor: ReadPlayCounterFrame},
+		"POPM": FrameType{id: "POPM", description: "Popularimeter", constructor: ReadPopularimeterFrame},
+		"POSS": FrameType{id: "POSS", description: "Position synchronisation frame", constructor: ReadDataFrame},
+		"RBUF": FrameType{id: "RBUF", description: "Recommended buffer size", constructor: ReadDataFrame},
+		"RVAD": FrameType{id: "RVAD", description: "Relative volume adjustment", constructor: ReadRelativeVolumeFrame},
+		"RVRB": FrameType{id: "RVRB", description: "Reverb", constructor: ReadDataFrame},
+		"SYLT": FrameType{id: "SYLT", description: "Synchronized lyric/text", constructor: ReadSyncLyricsFrame},
+		"SYTC": FrameType{id: "SYTC", description: "Synchronized tempo codes", constructor: ReadSyncTempoFrame},
+		"TALB": FrameType{id: "TALB", description: "Album/Movie/Show title", constructor: ReadTextFrame},
+		"TBPM": FrameType{id: "TBPM", description: "BPM (beats per minute)", constructor: ReadTextFrame},
+		"TCOM": FrameType{id: "TCOM", description: "Composer", constructor: ReadTextFrame},
+		"TCON": FrameType{id: "TCON", description: "Content type", constructor: ReadTextFrame},
+		"TCOP": FrameType{id: "TCOP", description: "Copyright message", constructor: ReadTextFrame},
+		"TDAT": FrameType{id: "TDAT", description: "Date", constructor: ReadTextFrame},
+		"TDLY": FrameType{id: "TDLY", description: "Playlist delay", constructor: ReadTextFrame},
+		"TENC": FrameType{id: "TENC", description: "Encoded by", constructor: ReadTextFrame},
+		"TEXT": FrameType{id: "TEXT", description: "Lyricist/Text writer", constructor: ReadTextFrame},
+		"TFLT": FrameType{id: "TFLT", description: "File type", constructor: ReadTextFrame},
+		"TIME": FrameType{id: "TIME", description: "Time", constructor: ReadTextFrame},
+		"TIT1": FrameType{id: "TIT1", description: "Content group description", constructor: ReadTextFrame},
+		"TIT2": FrameType{id: "TIT2", description: "Title/songname/content description", constructor: ReadTextFrame},
+		"TIT3": FrameType{id: "TIT3", description: "Subtitle/Description refinement", constructor: ReadTextFrame},
+		"TKEY": FrameType{id: "TKEY", description: "Initial key", constructor: ReadTextFrame},
+		"TLAN": FrameType{id: "TLAN", description: "Language(s)", constructor: ReadTextFrame},
+		"TLEN": FrameType{id: "TLEN", description: "Length", constructor: ReadTextFrame},
+		"TMED": FrameType{id: "TMED", description: "Media type", constructor: ReadTextFrame},
+		"TOAL": FrameType{id: "TOAL", description: "Original album/movie/show title", constructor: ReadTextFrame},
+		"TOFN": FrameType{id: "TOFN", description: "Original filename", constructor: ReadTextFrame},
+		"TOLY": FrameType{id: "TOLY", description: "Original lyricist(s)/text writer(s)", constructor: ReadTextFrame},
+		"TOPE": FrameType{id: "TOPE", description: "Original artist(s)/performer(s)", constructor: ReadTextFrame},
+		"TORY": FrameType{id: "TORY", description: "Original release year", constructor: ReadTextFrame},
+		"TOWN": FrameType{id: "TOWN", description: "File owner/licensee", constructor: ReadTextFrame},
+		"TPE1": FrameType{id: "TPE1", description: "Lead performer(s)/Soloist(s)", constructor: ReadTextFrame},
+		"TPE2": FrameType{id: "TPE2", description: "Band/orchestra/accompaniment", constructor: ReadTextFrame},
+		"TPE3": FrameType{id: "TPE3", description: "Conductor/performer refinement", constructor: ReadTextFrame},
+		"TPE4": FrameType{id: "TPE4", description: "Interpreted, remixed, or otherwise modified by", constructor: ReadTextFrame},
+		"TPOS": FrameType{id: "TPOS", description: "Part of a set", constructor: ReadTextFrame},
+		"TPUB": FrameType{id: "TPUB", description: "Publisher", constructor: ReadTextFrame},
+		"TRCK": FrameType{id: "TRCK", description: "Track number/Position in set", constructor: ReadTextFrame},
+		"TRDA": FrameType{id: "TRDA", description: "Recording dates", constructor: ReadTextFrame},
+		"TRSN": FrameType{id: "TRSN", description: "Internet radio station name", constructor: ReadTextFrame},
+		"TRSO": FrameType{id: "TRSO", description: "Internet radio station owner", constructor: ReadTextFrame},
+		"TSIZ": FrameType{id: "TSIZ", description: "Size", constructor: ReadTextFrame},
+		"TSRC": FrameType{id: "TSRC", description: "ISRC (international standard recording code)", constructor: ReadTextFrame},
+		"TSSE": FrameType{id: "TSSE", description: "Software/Hardware and settings used for encoding", constructor: ReadTextFrame},
+		"TYER": FrameType{id: "TYER", description: "Year", constructor: ReadTextFrame},
+		"TXXX": FrameType{id: "TXXX", description: "User defined text information frame", constructor: ReadDescTextFrame},
+		"UFID": FrameType{id: "UFID", description: "Unique file identifier", constructor: ReadIdFrame},
+		"USER": FrameType{id: "USER", description: "Terms of use", constructor: ReadTermsOfUseFrame},
+		"TCMP": FrameType{id: "TCMP", description: "Part of a compilation (iTunes extension)", constructor: ReadTextFrame},
+		"USLT": FrameType{id: "USLT", description: "Unsychronized lyric/text transcription", constructor: ReadUnsynchTextFrame},
+		"WCOM": FrameType{id: "WCOM", description: "Commercial information", constructor: ReadURLFrame},
+		"WCOP": FrameType{id: "WCOP", description: "Copyright/Legal information", constructor: ReadURLFrame},
+		"WOAF": FrameType{id: "WOAF", description: "Official audio file webpage", constructor: ReadURLFrame},
+		"WOAR": FrameType{id: "WOAR", description: "Official artist/performer webpage", constructor: ReadURLFrame},
+		"WOAS": FrameType{id: "WOAS", description: "Official audio source webpage", constructor: ReadURLFrame},
+		"WORS": FrameType{id: "WORS", description: "Official internet radio station homepage", constructor: ReadURLFrame},
+		"WPAY": FrameType{id: "WPAY", description: "Payment", constructor: ReadURLFrame},
+		"WPUB": FrameType{id: "WPUB", description: "Publishers official webpage", constructor: ReadURLFrame},
+		"WXXX": FrameType{id: "WXXX", description: "User defined URL link frame", constructor: ReadUserURLFrame},
 	}
 )
 
 func ParseV23Frame(reader io.Reader) Framer {
-	data := make([]byte, FrameHeaderSize)
-	if n, err := io.ReadFull(reader, data); n < FrameHeaderSize || err != nil {
-		return nil
+	return parsedFrame(readV23Frame(reader, frameOptions{}))
+}
+
+// Reads a frame, returning an error describing why the frame could not be
+// read, or io.EOF if the reader is at the end of the frames
+func ReadV23Frame(reader io.Reader) (Framer, error) {
+	return readV23Frame(reader, frameOptions{strict: true})
+}
+
+func readV23Frame(reader io.Reader, opts frameOptions) (Framer, error) {
+	data, err := readFrameHeader(reader, FrameHeaderSize)
+	if err != nil {
+		return nil, err
 	}
 
 	id := string(data[:4])
-	t, ok := V23FrameTypeMap[id]
+	t, ok := lookupFrameType(id, V23FrameTypeMap)
 	if !ok {
-		return nil
+		return nil, newFrameError(id, ErrInvalidFrameId)
 	}
 
 	size, err := encodedbytes.NormInt(data[4:8])
 	if err != nil {
-		return nil
+		return nil, newFrameError(id, err)
 	}
 
 	h := FrameHead{
@@ -143,19 +174,143 @@ func ParseV23Frame(reader io.Reader) Framer {
 	}
 
 	frameData := make([]byte, size)
-	if n, err := io.ReadFull(reader, frameData); n < int(size) || err != nil {
-		return nil
+	if n, err := io.ReadFull(reader, frameData); err != nil {
+		return nil, newFrameError(id, truncatedError(n, int(size)))
+	}
+
+	// The decompressed size, the encryption method and the group identifier
+	// come before the frame data, in the order of their flags
+	raw := h
+	rest := frameData
+	decompressedSize := -1
+
+	if isBitSet(h.formatFlags, V23FlagCompression) {
+		if len(rest) < decompressedSizeSize {
+			return untransformedFrame(raw, frameData, truncatedError(len(rest), decompressedSizeSize), opts.strict)
+		}
+
+		n, _ := encodedbytes.NormInt(rest[:decompressedSizeSize])
+		decompressedSize = int(n)
+		rest = rest[decompressedSizeSize:]
+	}
+
+	var cipher FrameCipher
+	if isBitSet(h.formatFlags, V23FlagEncryption) {
+		if len(rest) < 1 {
+			return untransformedFrame(raw, frameData, truncatedError(0, 1), opts.strict)
+		}
+
+		// Frames encrypted with unknown methods cannot be decoded, so they
+		// are kept as opaque data along with their flags
+		var ok bool
+		if cipher, ok = opts.frameCipher(rest[0]); !ok {
+			return ParseDataFrame(raw, frameData), nil
+		}
+
+		h.encrypted = true
+		h.method = rest[0]
+		rest = rest[1:]
+	}
+
+	if isBitSet(h.formatFlags, V23FlagGrouping) {
+		if len(rest) < 1 {
+			return untransformedFrame(raw, frameData, truncatedError(0, 1), opts.strict)
+		}
+
+		h.grouped = true
+		h.groupId = rest[0]
+		rest = rest[1:]
+	}
+
+	// Frames are compressed before they are encrypted
+	if cipher != nil {
+		if rest, err = cipher.Decrypt(rest); err != nil {
+			return untransformedFrame(raw, frameData, err, opts.strict)
+		}
 	}
 
-	return t.constructor(h, frameData)
+	if isBitSet(h.formatFlags, V23FlagCompression) {
+		if rest, err = decompressFrameData(rest, decompressedSize); err != nil {
+			return untransformedFrame(raw, frameData, err, opts.strict)
+		}
+	}
+
+	frameData = rest
+	h.formatFlags &^= 1<<V23FlagCompression | 1<<V23FlagEncryption | 1<<V23FlagGrouping
+	h.size = uint32(len(frameData))
+
+	f, err := constructFrame(h, frameData, opts.strict)
+	if err != nil {
+		return nil, newFrameError(id, err)
+	}
+
+	return f, nil
+}
+
+// End of the frames at the start of the data, found from the sizes in the
+// frame headers without reading the frames
+func v23FramesEnd(data []byte) int {
+	end := 0
+	for len(data)-end >= FrameHeaderSize && data[end] != 0 {
+		size, _ := encodedbytes.NormInt(data[end+4 : end+8])
+		if int(size) > len(data)-end-FrameHeaderSize {
+			break
+		}
+
+		end += FrameHeaderSize + int(size)
+	}
+
+	return end
 }
 
+// Frames that cannot be written, such as encrypted frames whose cipher fails,
+// return nil
 func V23Bytes(f Framer) []byte {
+	data, _ := v23Bytes(f, frameOptions{})
+	return data
+}
+
+func v23Bytes(f Framer, opts frameOptions) ([]byte, error) {
+	data := f.Bytes()
+	formatFlags := f.FormatFlags()
+
+	// Frames that could not be decrypted are written as they were read
+	if !isBitSet(formatFlags, V23FlagEncryption) {
+		var prefix []byte
+
+		if opts.compression && compressibleFrameIds[f.Id()] {
+			if compressed, ok := compressFrameData(data); ok {
+				prefix = append(prefix, encodedbytes.NormBytes(uint32(len(data)))...)
+				formatFlags |= 1 << V23FlagCompression
+				data = compressed
+			}
+		}
+
+		// Frames that cannot be encrypted are never written in the clear
+		if method, ok := f.EncryptionMethod(); ok {
+			encrypted, err := encryptFrameData(f, data, opts)
+			if err != nil {
+				return nil, newFrameError(f.Id(), err)
+			}
+
+			prefix = append(prefix, method)
+			formatFlags |= 1 << V23FlagEncryption
+			data = encrypted
+		}
+
+		if groupId, ok := f.GroupId(); ok {
+			prefix = append(prefix, groupId)
+			formatFlags |= 1 << V23FlagGrouping
+		}
+
+		data = append(prefix, data...)
+	}
+
 	headBytes := make([]byte, 0, FrameHeaderSize)
 
 	headBytes = append(headBytes, f.Id()...)
-	headBytes = append(headBytes, encodedbytes.NormBytes(uint32(f.Size()))...)
-	headBytes = append(headBytes, f.StatusFlags(), f.FormatFlags())
+	headBytes = append(headBytes, encodedbytes.NormBytes(uint32(len(data)))...)
+	headBytes = append(headBytes, f.StatusFlags(), formatFlags)
 
-	return append(headBytes, f.Bytes()...)
+	return append(headBytes, data...), nil
 }
@@ -5,6 +5,7 @@ package v2
 
 import (
 	"bytes"
+	"compress/zlib"
 	"testing"
 )
 
@@ -30,3 +31,99 @@ func TestV23Frame(t *testing.T) {
 		t.Errorf("V23Bytes produces different byte slice, expected %v not %v", textData, b)
 	}
 }
+
+func TestV23UnknownFrame(t *testing.T) {
+	data := []byte{88, 89, 90, 49, 0, 0, 0, 4, 0x40, 0, 1, 2, 3, 4}
+	frame := ParseV23Frame(bytes.NewReader(data))
+	if frame == nil {
+		t.Fatal("ParseV23Frame returned nil for unknown frame")
+	}
+
+	if _, ok := frame.(*DataFrame); !ok {
+		t.Errorf("ParseV23Frame on unknown frame returns wrong type")
+	}
+
+	if b := V23Bytes(frame); !bytes.Equal(data, b) {
+		t.Errorf("V23Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+}
+
+func TestV23TagUnknownFrame(t *testing.T) {
+	tag := NewTag(3)
+	tag.AddFrames(NewDataFrame(FrameType{id: "XYZ1"}, []byte{1, 2, 3}))
+	tag.AddFrames(NewTextFrame(V23FrameTypeMap["TIT2"], "Nice Life"))
+
+	parsed := ParseTag(bytes.NewReader(tag.Bytes()))
+	if parsed == nil {
+		t.Fatal("ParseTag could not parse tag with unknown frame")
+	}
+
+	if s := parsed.Title(); s != "Nice Life" {
+		t.Errorf("ParseTag incorrect title, %v", s)
+	}
+
+	if f, ok := parsed.Frame("XYZ1").(*DataFrame); !ok || !bytes.Equal(f.Data(), []byte{1, 2, 3}) {
+		t.Errorf("ParseTag did not keep unknown frame")
+	}
+}
+
+func TestV23TagUnsynchronization(t *testing.T) {
+	tag := NewTag(3)
+	tag.SetUnsynchronization(true)
+	tag.AddFrames(NewPrivateFrame(V23FrameTypeMap["PRIV"], "o", []byte{0xff, 0xfb, 0xff}))
+
+	data := tag.Bytes()
+	if !bytes.Contains(data, []byte{0xff, 0x00, 0xfb, 0xff, 0x00}) {
+		t.Errorf("tag data is not unsynchronized: %v", data)
+	}
+
+	parsed := ParseTag(bytes.NewReader(data))
+	if parsed == nil {
+		t.Fatal("ParseTag could not parse unsynchronized tag")
+	}
+
+	if !parsed.Unsynchronization() {
+		t.Error("ParseTag did not detect unsynchronization")
+	}
+
+	frame, ok := parsed.Frame("PRIV").(*PrivateFrame)
+	if !ok || !bytes.Equal(frame.Bytes(), []byte{'o', 0, 0xff, 0xfb, 0xff}) {
+		t.Errorf("unsynchronized frame data not restored: %v", parsed.Frame("PRIV"))
+	}
+
+	if b := parsed.Bytes(); !bytes.Equal(data, b) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+}
+
+func TestV23CompressedGroupedFrame(t *testing.T) {
+	text := []byte{0, 78, 105, 99, 101, 32, 76, 105, 102, 101}
+
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	w.Write(text)
+	w.Close()
+
+	// Decompressed size and group identifier precede the compressed data
+	frameData := append([]byte{0, 0, 0, byte(len(text)), 7}, compressed.Bytes()...)
+	data := append([]byte{84, 73, 84, 50, 0, 0, 0, byte(len(frameData)), 0, 0xa0}, frameData...)
+
+	frame, err := ReadV23Frame(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := frame.String(); s != "Nice Life" {
+		t.Errorf("ReadV23Frame incorrect text, %v", s)
+	}
+
+	if groupId, ok := frame.GroupId(); !ok || groupId != 7 {
+		t.Errorf("ReadV23Frame incorrect group identifier %d", groupId)
+	}
+
+	// Only the group identifier is kept when written without compression
+	expected := append([]byte{84, 73, 84, 50, 0, 0, 0, byte(len(text) + 1), 0, 0x20, 7}, text...)
+	if b := V23Bytes(frame); !bytes.Equal(expected, b) {
+		t.Errorf("V23Bytes produces different byte slice, expected %v not %v", expected, b)
+	}
+}
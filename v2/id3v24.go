@@ -0,0 +1,315 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"io"
+)
+
+const (
+	// ID3v2.4 frame format flag bits
+	V24FlagGrouping            = 6
+	V24FlagCompression         = 3
+	V24FlagEncryption          = 2
+	V24FlagUnsynchronization   = 1
+	V24FlagDataLengthIndicator = 0
+
+	dataLengthIndicatorSize = 4
+)
+
+var (
+	// Common frame IDs
+	V24CommonFrame = map[string]FrameType{
+		"Title":           V24FrameTypeMap["TIT2"],
+		"Artist":          V24FrameTypeMap["TPE1"],
+		"Album":           V24FrameTypeMap["TALB"],
+		"Year":            V24FrameTypeMap["TDRC"],
+		"Genre":           V24FrameTypeMap["TCON"],
+		"Comments":        V24FrameTypeMap["COMM"],
+		"Picture":         V24FrameTypeMap["APIC"],
+		"Popularimeter":   V24FrameTypeMap["POPM"],
+		"PlayCounter":     V24FrameTypeMap["PCNT"],
+		"ArtistURL":       V24FrameTypeMap["WOAR"],
+		"SourceURL":       V24FrameTypeMap["WOAS"],
+		"Object":          V24FrameTypeMap["GEOB"],
+		"Private":         V24FrameTypeMap["PRIV"],
+		"UserText":        V24FrameTypeMap["TXXX"],
+		"InvolvedPeople":  V24FrameTypeMap["TIPL"],
+		"MusicianCredits": V24FrameTypeMap["TMCL"],
+		"UniqueID":        V24FrameTypeMap["UFID"],
+	}
+
+	// V24DeprecatedTypeMap contains deprecated frame IDs from ID3v2.3
+	// The date and time frames are all combined into a single TDRC frame
+	V24DeprecatedTypeMap = map[string]string{
+		"IPLS": "TIPL", "TDAT": "TDRC", "TIME": "TDRC", "TORY": "TDOR",
+		"TRDA": "TDRC", "TYER": "TDRC",
+	}
+
+	// V24FrameTypeMap specifies the frame IDs and constructors allowed in ID3v2.4
+	V24FrameTypeMap = map[string]FrameType{
+		"AENC": FrameType{id: "AENC", description: "Audio encryption", constructor: ReadAudioEncryptionFrame},
+		"APIC": FrameType{id: "APIC", description: "Attached picture", constructor: ReadImageFrame},
+		"ASPI": FrameType{id: "ASPI", description: "Audio seek point index", constructor: ReadDataFrame},
+		"COMM": FrameType{id: "COMM", description: "Comments", constructor: ReadUnsynchTextFrame},
+		"COMR": FrameType{id: "COMR", description: "Commercial frame", constructor: ReadCommercialFrame},
+		"ENCR": FrameType{id: "ENCR", description: "Encryption method registration", constructor: ReadEncryptionMethodFrame},
+		"EQU2": FrameType{id: "EQU2", description: "Equalisation (2)", constructor: ReadDataFrame},
+		"ETCO": FrameType{id: "ETCO", description: "Event timing codes", constructor: ReadEventTimingFrame},
+		"GEOB": FrameType{id: "GEOB", description: "General encapsulated object", constructor: ReadGEOBFrame},
+		"GRID": FrameType{id: "GRID", description: "Group identification registration", constructor: ReadGroupIdFrame},
+		"LINK": FrameType{id: "LINK", description: "Linked information", constructor: ReadDataFrame},
+		"MCDI": FrameType{id: "MCDI", description: "Music CD identifier", constructor: ReadMCDIFrame},
+		"MLLT": FrameType{id: "MLLT", description: "MPEG location lookup table", constructor: ReadMLLTFrame},
+		"OWNE": FrameType{id: "OWNE", description: "Ownership frame", constructor: ReadOwnershipFrame},
+		"PRIV": FrameType{id: "PRIV", description: "Private frame", constructor: ReadPrivateFrame},
+		"PCNT": FrameType{id: "PCNT", description: "Play counter", constructor: ReadPlayCounterFrame},
+		"POPM": FrameType{id: "POPM", description: "Popularimeter", constructor: ReadPopularimeterFrame},
+		"POSS": FrameType{id: "POSS", description: "Position synchronisation frame", constructor: ReadDataFrame},
+		"RBUF": FrameType{id: "RBUF", description: "Recommended buffer size", constructor: ReadDataFrame},
+		"RVA2": FrameType{id: "RVA2", description: "Relative volume adjustment (2)", constructor: ReadRVA2Frame},
+		"RVRB": FrameType{id: "RVRB", description: "Reverb", constructor: ReadDataFrame},
+		"SEEK": FrameType{id: "SEEK", description: "Seek frame", constructor: ReadSeekFrame},
+		"SIGN": FrameType{id: "SIGN", description: "Signature frame", constructor: ReadDataFrame},
+		"SYLT": FrameType{id: "SYLT", description: "Synchronised lyric/text", constructor: ReadSyncLyricsFrame},
+		"SYTC": FrameType{id: "SYTC", description: "Synchronised tempo codes", constructor: ReadSyncTempoFrame},
+		"TALB": FrameType{id: "TALB", description: "Album/Movie/Show title", constructor: ReadTextFrame},
+		"TBPM": FrameType{id: "TBPM", description: "BPM (beats per minute)", constructor: ReadTextFrame},
+		"TCOM": FrameType{id: "TCOM", description: "Composer", constructor: ReadTextFrame},
+		"TCON": FrameType{id: "TCON", description: "Content type", constructor: ReadTextFrame},
+		"TCOP": FrameType{id: "TCOP", description: "Copyright message", constructor: ReadTextFrame},
+		"TDEN": FrameType{id: "TDEN", description: "Encoding time", constructor: ReadTextFrame},
+		"TDLY": FrameType{id: "TDLY", description: "Playlist delay", constructor: ReadTextFrame},
+		"TDOR": FrameType{id: "TDOR", description: "Original release time", constructor: ReadTextFrame},
+		"TDRC": FrameType{id: "TDRC", description: "Recording time", constructor: ReadTextFrame},
+		"TDRL": FrameType{id: "TDRL", description: "Release time", constructor: ReadTextFrame},
+		"TDTG": FrameType{id: "TDTG", description: "Tagging time", constructor: ReadTextFrame},
+		"TENC": FrameType{id: "TENC", description: "Encoded by", constructor: ReadTextFrame},
+		"TEXT": FrameType{id: "TEXT", description: "Lyricist/Text writer", constructor: ReadTextFrame},
+		"TFLT": FrameType{id: "TFLT", description: "File type", constructor: ReadTextFrame},
+		"TIPL": FrameType{id: "TIPL", description: "Involved people list", constructor: ReadInvolvedPeopleFrame},
+		"TIT1": FrameType{id: "TIT1", description: "Content group description", constructor: ReadTextFrame},
+		"TIT2": FrameType{id: "TIT2", description: "Title/songname/content description", constructor: ReadTextFrame},
+		"TIT3": FrameType{id: "TIT3", description: "Subtitle/Description refinement", constructor: ReadTextFrame},
+		"TKEY": FrameType{id: "TKEY", description: "Initial key", constructor: ReadTextFrame},
+		"TLAN": FrameType{id: "TLAN", description: "Language(s)", constructor: ReadTextFrame},
+		"TLEN": FrameType{id: "TLEN", description: "Length", constructor: ReadTextFrame},
+		"TMCL": FrameType{id: "TMCL", description: "Musician credits list", constructor: ReadInvolvedPeopleFrame},
+		"TMED": FrameType{id: "TMED", description: "Media type", constructor: ReadTextFrame},
+		"TMOO": FrameType{id: "TMOO", description: "Mood", constructor: ReadTextFrame},
+		"TOAL": FrameType{id: "TOAL", description: "Original album/movie/show title", constructor: ReadTextFrame},
+		"TOFN": FrameType{id: "TOFN", description: "Original filename", constructor: ReadTextFrame},
+		"TOLY": FrameType{id: "TOLY", description: "Original lyricist(s)/text writer(s)", constructor: ReadTextFrame},
+		"TOPE": FrameType{id: "TOPE", description: "Original artist(s)/performer(s)", constructor: ReadTextFrame},
+		"TOWN": FrameType{id: "TOWN", description: "File owner/licensee", constructor: ReadTextFrame},
+		"TPE1": FrameType{id: "TPE1", description: "Lead performer(s)/Soloist(s)", constructor: ReadTextFrame},
+		"TPE2": FrameType{id: "TPE2", description: "Band/orchestra/accompaniment", constructor: ReadTextFrame},
+		"TPE3": FrameType{id: "TPE3", description: "Conductor/performer refinement", constructor: ReadTextFrame},
+		"TPE4": FrameType{id: "TPE4", description: "Interpreted, remixed, or otherwise modified by", constructor: ReadTextFrame},
+		"TPOS": FrameType{id: "TPOS", description: "Part of a set", constructor: ReadTextFrame},
+		"TPRO": FrameType{id: "TPRO", description: "Produced notice", constructor: ReadTextFrame},
+		"TPUB": FrameType{id: "TPUB", description: "Publisher", constructor: ReadTextFrame},
+		"TRCK": FrameType{id: "TRCK", description: "Track number/Position in set", constructor: ReadTextFrame},
+		"TRSN": FrameType{id: "TRSN", description: "Internet radio station name", constructor: ReadTextFrame},
+		"TRSO": FrameType{id: "TRSO", description: "Internet radio station owner", constructor: ReadTextFrame},
+		"TSOA": FrameType{id: "TSOA", description: "Album sort order", constructor: ReadTextFrame},
+		"TSOP": FrameType{id: "TSOP", description: "Performer sort order", constructor: ReadTextFrame},
+		"TSOT": FrameType{id: "TSOT", description: "Title sort order", constructor: ReadTextFrame},
+		"TSRC": FrameType{id: "TSRC", description: "ISRC (international standard recording code)", constructor: ReadTextFrame},
+		"TSSE": FrameType{id: "TSSE", description: "Software/Hardware and settings used for encoding", constructor: ReadTextFrame},
+		"TSST": FrameType{id: "TSST", description: "Set subtitle", constructor: ReadTextFrame},
+		"TXXX": FrameType{id: "TXXX", description: "User defined text information frame", constructor: ReadDescTextFrame},
+		"UFID": FrameType{id: "UFID", description: "Unique file identifier", constructor: ReadIdFrame},
+		"USER": FrameType{id: "USER", description: "Terms of use", constructor: ReadTermsOfUseFrame},
+		"TCMP": FrameType{id: "TCMP", description: "Part of a compilation (iTunes extension)", constructor: ReadTextFrame},
+		"USLT": FrameType{id: "USLT", description: "Unsynchronised lyric/text transcription", constructor: ReadUnsynchTextFrame},
+		"WCOM": FrameType{id: "WCOM", description: "Commercial information", constructor: ReadURLFrame},
+		"WCOP": FrameType{id: "WCOP", description: "Copyright/Legal information", constructor: ReadURLFrame},
+		"WOAF": FrameType{id: "WOAF", description: "Official audio file webpage", constructor: ReadURLFrame},
+		"WOAR": FrameType{id: "WOAR", description: "Official artist/performer webpage", constructor: ReadURLFrame},
+		"WOAS": FrameType{id: "WOAS", description: "Official audio source webpage", constructor: ReadURLFrame},
+		"WORS": FrameType{id: "WORS", description: "Official internet radio station homepage", constructor: ReadURLFrame},
+		"WPAY": FrameType{id: "WPAY", description: "Payment", constructor: ReadURLFrame},
+		"WPUB": FrameType{id: "WPUB", description: "Publishers official webpage", constructor: ReadURLFrame},
+		"WXXX": FrameType{id: "WXXX", description: "User defined URL link frame", constructor: ReadUserURLFrame},
+	}
+)
+
+func ParseV24Frame(reader io.Reader) Framer {
+	return parsedFrame(readV24Frame(reader, frameOptions{}))
+}
+
+// Reads a frame, returning an error describing why the frame could not be
+// read, or io.EOF if the reader is at the end of the frames
+func ReadV24Frame(reader io.Reader) (Framer, error) {
+	return readV24Frame(reader, frameOptions{strict: true})
+}
+
+func readV24Frame(reader io.Reader, opts frameOptions) (Framer, error) {
+	data, err := readFrameHeader(reader, FrameHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+
+	id := string(data[:4])
+	t, ok := lookupFrameType(id, V24FrameTypeMap)
+	if !ok {
+		return nil, newFrameError(id, ErrInvalidFrameId)
+	}
+
+	size, err := encodedbytes.SynchInt(data[4:8])
+	if err != nil {
+		return nil, newFrameError(id, err)
+	}
+
+	h := FrameHead{
+		FrameType:   t,
+		statusFlags: data[8],
+		formatFlags: data[9],
+		size:        size,
+	}
+
+	frameData := make([]byte, size)
+	if n, err := io.ReadFull(reader, frameData); err != nil {
+		return nil, newFrameError(id, truncatedError(n, int(size)))
+	}
+
+	if opts.unsynchronization || isBitSet(h.formatFlags, V24FlagUnsynchronization) {
+		frameData = encodedbytes.Resynchronize(frameData)
+		h.formatFlags &^= 1 << V24FlagUnsynchronization
+		h.size = uint32(len(frameData))
+	}
+
+	// The group identifier, the encryption method and the data length
+	// indicator come before the frame data, in the order of their flags
+	raw := h
+	rest := frameData
+	decompressedSize := -1
+
+	if isBitSet(h.formatFlags, V24FlagGrouping) {
+		if len(rest) < 1 {
+			return untransformedFrame(raw, frameData, truncatedError(0, 1), opts.strict)
+		}
+
+		h.grouped = true
+		h.groupId = rest[0]
+		rest = rest[1:]
+	}
+
+	var cipher FrameCipher
+	if isBitSet(h.formatFlags, V24FlagEncryption) {
+		if len(rest) < 1 {
+			return untransformedFrame(raw, frameData, truncatedError(0, 1), opts.strict)
+		}
+
+		// Frames encrypted with unknown methods cannot be decoded, so they
+		// are kept as opaque data along with their flags
+		var ok bool
+		if cipher, ok = opts.frameCipher(rest[0]); !ok {
+			return ParseDataFrame(raw, frameData), nil
+		}
+
+		h.encrypted = true
+		h.method = rest[0]
+		rest = rest[1:]
+	}
+
+	if isBitSet(h.formatFlags, V24FlagDataLengthIndicator) {
+		if len(rest) < dataLengthIndicatorSize {
+			return untransformedFrame(raw, frameData, truncatedError(len(rest), dataLengthIndicatorSize), opts.strict)
+		}
+
+		n, err := encodedbytes.SynchInt(rest[:dataLengthIndicatorSize])
+		if err != nil {
+			return untransformedFrame(raw, frameData, err, opts.strict)
+		}
+
+		decompressedSize = int(n)
+		rest = rest[dataLengthIndicatorSize:]
+	}
+
+	// Frames are compressed before they are encrypted
+	if cipher != nil {
+		if rest, err = cipher.Decrypt(rest); err != nil {
+			return untransformedFrame(raw, frameData, err, opts.strict)
+		}
+	}
+
+	if isBitSet(h.formatFlags, V24FlagCompression) {
+		if rest, err = decompressFrameData(rest, decompressedSize); err != nil {
+			return untransformedFrame(raw, frameData, err, opts.strict)
+		}
+	}
+
+	frameData = rest
+	h.formatFlags &^= 1<<V24FlagGrouping | 1<<V24FlagCompression | 1<<V24FlagEncryption | 1<<V24FlagDataLengthIndicator
+	h.size = uint32(len(frameData))
+
+	f, err := constructFrame(h, frameData, opts.strict)
+	if err != nil {
+		return nil, newFrameError(id, err)
+	}
+
+	return f, nil
+}
+
+// Frames that cannot be written, such as encrypted frames whose cipher fails,
+// return nil
+func V24Bytes(f Framer) []byte {
+	data, _ := v24Bytes(f, frameOptions{})
+	return data
+}
+
+func v24Bytes(f Framer, opts frameOptions) ([]byte, error) {
+	data := f.Bytes()
+	formatFlags := f.FormatFlags()
+
+	// Frames that could not be decrypted are written as they were read
+	if !isBitSet(formatFlags, V24FlagEncryption) {
+		var prefix, dataLength []byte
+
+		if groupId, ok := f.GroupId(); ok {
+			prefix = append(prefix, groupId)
+			formatFlags |= 1 << V24FlagGrouping
+		}
+
+		// Compressed frames also need a data length indicator
+		if opts.compression && compressibleFrameIds[f.Id()] {
+			if compressed, ok := compressFrameData(data); ok {
+				dataLength = encodedbytes.SynchBytes(uint32(len(data)))
+				formatFlags |= 1<<V24FlagCompression | 1<<V24FlagDataLengthIndicator
+				data = compressed
+			}
+		}
+
+		// Frames that cannot be encrypted are never written in the clear
+		if method, ok := f.EncryptionMethod(); ok {
+			encrypted, err := encryptFrameData(f, data, opts)
+			if err != nil {
+				return nil, newFrameError(f.Id(), err)
+			}
+
+			prefix = append(prefix, method)
+			formatFlags |= 1 << V24FlagEncryption
+			data = encrypted
+		}
+
+		prefix = append(prefix, dataLength...)
+		data = append(prefix, data...)
+	}
+
+	if opts.unsynchronization {
+		data = encodedbytes.Unsynchronize(data)
+		formatFlags |= 1 << V24FlagUnsynchronization
+	}
+
+	headBytes := make([]byte, 0, FrameHeaderSize)
+
+	headBytes = append(headBytes, f.Id()...)
+	headBytes = append(headBytes, encodedbytes.SynchBytes(uint32(len(data)))...)
+	headBytes = append(headBytes, f.StatusFlags(), formatFlags)
+
+	return append(headBytes, data...), nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestV24Frame(t *testing.T) {
+	text := strings.Repeat("a", 200)
+	textData := append([]byte{84, 73, 84, 50, 0, 0, 1, 73, 0, 0, 3}, text...)
+	frame := ParseV24Frame(bytes.NewReader(textData))
+	textFrame, ok := frame.(*TextFrame)
+	if !ok {
+		t.Fatalf("ParseV24Frame on text data returns wrong type")
+	}
+
+	if ft := textFrame.Text(); ft != text {
+		t.Errorf("ParseV24Frame incorrect text, expected %s not %s", text, ft)
+	}
+
+	const encoding = "UTF-8"
+	if e := textFrame.Encoding(); e != encoding {
+		t.Errorf("ParseV24Frame incorrect encoding, expected %s not %s", encoding, e)
+	}
+
+	if b := V24Bytes(frame); !bytes.Equal(textData, b) {
+		t.Errorf("V24Bytes produces different byte slice, expected %v not %v", textData, b)
+	}
+}
+
+func TestV24FrameDataLengthIndicator(t *testing.T) {
+	textData := []byte{84, 80, 69, 49, 0, 0, 0, 9, 0, 1, 0, 0, 0, 5, 3, 89, 97, 110, 103}
+	frame := ParseV24Frame(bytes.NewReader(textData))
+	textFrame, ok := frame.(*TextFrame)
+	if !ok {
+		t.Fatalf("ParseV24Frame on text data returns wrong type")
+	}
+
+	const text = "Yang"
+	if ft := textFrame.Text(); ft != text {
+		t.Errorf("ParseV24Frame incorrect text, expected %s not %s", text, ft)
+	}
+
+	if flags := textFrame.FormatFlags(); flags != 0 {
+		t.Errorf("ParseV24Frame kept data length indicator flag, flags %08b", flags)
+	}
+}
+
+func TestV24MultipleTexts(t *testing.T) {
+	f := NewTextFrame(V24FrameTypeMap["TPE1"], "")
+	f.SetEncoding("UTF-8")
+
+	artists := []string{"Paloalto", "Basick"}
+	if err := f.SetTexts(artists...); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := ParseV24Frame(bytes.NewReader(V24Bytes(f))).(*TextFrame)
+	if texts := parsed.Texts(); !reflect.DeepEqual(texts, artists) {
+		t.Errorf("Texts returned %q, expected %q", texts, artists)
+	}
+}
+
+func TestV24MultipleTextsByteOrderMarks(t *testing.T) {
+	// Every UTF-16 value has its own byte order mark
+	for _, data := range [][]byte{
+		[]byte("\x01\xff\xfeA\x00\x00\x00\xff\xfeB\x00"),
+		[]byte("\x01\xff\xfeA\x00\x00\x00\xfe\xff\x00B"),
+	} {
+		frame, err := ReadTextFrame(FrameHead{FrameType: V24FrameTypeMap["TPE1"], size: uint32(len(data))}, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f := frame.(*TextFrame)
+		if texts := f.Texts(); !reflect.DeepEqual(texts, []string{"A", "B"}) {
+			t.Errorf("Texts returned %q for %v", texts, data)
+		}
+
+		expected := []byte("\x01\xff\xfeA\x00\x00\x00\xff\xfeB\x00")
+		if b := f.Bytes(); !bytes.Equal(b, expected) {
+			t.Errorf("Bytes produces %v, expected %v", b, expected)
+		}
+	}
+}
+
+func TestV24TagFooter(t *testing.T) {
+	tag := NewTag(4)
+	tag.SetFooter(true)
+	tag.SetTitle("Nice Life")
+	tag.SetYear("2013")
+
+	data := tag.Bytes()
+	if footer := data[len(data)-FooterSize:]; string(footer[:3]) != "3DI" {
+		t.Fatalf("Bytes does not end with footer: %v", footer)
+	}
+
+	parsed := ParseTag(bytes.NewReader(data))
+	if parsed == nil {
+		t.Fatal("ParseTag could not parse tag with footer")
+	}
+
+	if !parsed.Footer() {
+		t.Error("ParseTag did not detect footer")
+	}
+
+	if s := parsed.Title(); s != "Nice Life" {
+		t.Errorf("ParseTag incorrect title, %v", s)
+	}
+
+	if s := parsed.Year(); s != "2013" {
+		t.Errorf("ParseTag incorrect year, %v", s)
+	}
+
+	if padding := parsed.Padding(); padding != 0 {
+		t.Errorf("tag with footer has %d bytes of padding", padding)
+	}
+}
+
+func TestV24FrameUnsynchronization(t *testing.T) {
+	frame := NewPrivateFrame(V24FrameTypeMap["PRIV"], "o", []byte{0xff, 0xe0})
+	data, err := v24Bytes(frame, frameOptions{unsynchronization: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{80, 82, 73, 86, 0, 0, 0, 5, 0, 2, 'o', 0, 0xff, 0, 0xe0}
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("v24Bytes produces %v, expected %v", data, expected)
+	}
+
+	parsed, ok := ParseV24Frame(bytes.NewReader(data)).(*PrivateFrame)
+	if !ok || !bytes.Equal(parsed.Bytes(), frame.Bytes()) {
+		t.Errorf("ParseV24Frame did not resynchronize frame: %v", parsed)
+	}
+
+	if flags := parsed.FormatFlags(); flags != 0 {
+		t.Errorf("ParseV24Frame kept unsynchronization flag, flags %08b", flags)
+	}
+}
+
+func TestV24TagFrameCompression(t *testing.T) {
+	lyrics := strings.Repeat("Nice life, nice life\n", 20)
+
+	tag := NewTag(4)
+	tag.SetFrameCompression(true)
+	tag.SetTitle("Nice Life")
+	tag.AddFrames(NewUnsynchTextFrame(V24FrameTypeMap["USLT"], "", lyrics))
+
+	if size := tag.RealSize(); size >= len(lyrics) {
+		t.Errorf("tag with compressed lyrics has size %d", size)
+	}
+
+	data := tag.Bytes()
+
+	parsed, err := ReadTag(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frame, ok := parsed.Frame("USLT").(*UnsynchTextFrame)
+	if !ok {
+		t.Fatal("ReadTag did not read compressed lyrics frame")
+	}
+
+	if s := frame.Text(); s != lyrics {
+		t.Errorf("ReadTag incorrect lyrics, %v", s)
+	}
+
+	if flags := frame.FormatFlags(); flags != 0 {
+		t.Errorf("ReadTag kept format flags %08b of decompressed frame", flags)
+	}
+
+	// Text frames are not compressed
+	if !bytes.Contains(data, []byte("Nice Life")) {
+		t.Error("Bytes compressed title")
+	}
+}
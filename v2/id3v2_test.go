@@ -0,0 +1,55 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTagPaddingPolicy(t *testing.T) {
+	tag := NewTag(3)
+	if err := tag.SetPaddingPolicy(&PaddingPolicy{Min: 0, Target: 4096, Max: 8192}); err != nil {
+		t.Fatal(err)
+	}
+
+	tag.SetTitle("Nice Life")
+	if padding := tag.Padding(); padding != 4096 {
+		t.Errorf("tag grown past its padding has %d bytes of padding", padding)
+	}
+
+	// Small changes are taken up by the padding
+	size := tag.Size()
+	tag.SetArtist("Michael Yang")
+	if s := tag.Size(); s != size {
+		t.Errorf("tag size changed from %d to %d", size, s)
+	}
+
+	parsed := ParseTag(bytes.NewReader(tag.Bytes()))
+	if padding := parsed.Padding(); padding != tag.Padding() {
+		t.Errorf("ParseTag incorrect padding, %d", padding)
+	}
+
+	// Removing a large frame leaves too much padding
+	parsed.SetPaddingPolicy(&PaddingPolicy{Min: 0, Target: 4096, Max: 8192})
+	parsed.AddFrames(NewTextFrame(V23FrameTypeMap["TCOM"], strings.Repeat("Composer", 2000)))
+	parsed.DeleteFrames("TCOM")
+	if padding := parsed.Padding(); padding != 4096 {
+		t.Errorf("tag shrunk past its maximum padding has %d bytes of padding", padding)
+	}
+
+	// The zero policy strips padding
+	if err := parsed.SetPaddingPolicy(&PaddingPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if padding := parsed.Padding(); padding != 0 {
+		t.Errorf("tag with stripped padding has %d bytes of padding", padding)
+	}
+
+	if err := parsed.SetPaddingPolicy(&PaddingPolicy{Min: 10, Target: 5, Max: 20}); err == nil {
+		t.Error("SetPaddingPolicy did not return error for unordered policy")
+	}
+}
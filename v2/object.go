@@ -0,0 +1,264 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"strings"
+)
+
+// GEOBFrame represents general encapsulated object frames, which embed a file
+// of any type
+type GEOBFrame struct {
+	DataFrame
+	encoding    byte
+	mimeType    string
+	filename    string
+	description string
+}
+
+// Creates a general encapsulated object frame
+// The filename and description are encoded as ISO-8859-1 when possible, and
+// as UTF-16 otherwise
+func NewGEOBFrame(ft FrameType, mimeType, filename, description string, data []byte) *GEOBFrame {
+	var encoding byte
+	encodedFilename, err := encodedbytes.Encoders[encoding].ConvertString(filename)
+	encodedDescription, err2 := encodedbytes.Encoders[encoding].ConvertString(description)
+	if err != nil || err2 != nil {
+		encoding = encodedbytes.IndexForEncoding("UTF-16")
+		encodedFilename, _ = encodedbytes.Encoders[encoding].ConvertString(filename)
+		encodedDescription, _ = encodedbytes.Encoders[encoding].ConvertString(description)
+	}
+
+	mimeType = strings.TrimRight(mimeType, "\x00")
+	nullLength := encodedbytes.EncodingNullLengthForIndex(encoding)
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(1 + len(mimeType) + 1 + len(encodedFilename) + nullLength + len(encodedDescription) + nullLength + len(data)),
+	}
+
+	return &GEOBFrame{
+		DataFrame:   DataFrame{head, data},
+		encoding:    encoding,
+		mimeType:    mimeType,
+		filename:    filename,
+		description: description,
+	}
+}
+
+func ParseGEOBFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadGEOBFrame(head, data))
+}
+
+func ReadGEOBFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := new(GEOBFrame)
+	f.FrameHead = head
+	rd := encodedbytes.NewReader(data)
+
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
+	}
+
+	if f.mimeType, err = rd.ReadNullTermString(encodedbytes.NativeEncoding); err != nil {
+		return nil, err
+	}
+
+	if f.filename, err = rd.ReadNullTermString(f.encoding); err != nil {
+		return nil, err
+	}
+
+	if f.description, err = rd.ReadNullTermString(f.encoding); err != nil {
+		return nil, err
+	}
+
+	if f.data, err = rd.ReadRest(); err != nil {
+		return nil, err
+	}
+
+	size, err := f.encodedSize()
+	if err != nil {
+		return nil, err
+	}
+
+	f.size = uint32(size)
+	return f, nil
+}
+
+// Size of the frame as it is written
+// The filename and description are written with the byte order marks they
+// are encoded with, which need not match how they were read
+func (f GEOBFrame) encodedSize() (int, error) {
+	filename, err := encodedbytes.Encoders[f.encoding].ConvertString(f.filename)
+	if err != nil {
+		return 0, err
+	}
+
+	description, err := encodedbytes.Encoders[f.encoding].ConvertString(f.description)
+	if err != nil {
+		return 0, err
+	}
+
+	nullLength := encodedbytes.EncodingNullLengthForIndex(f.encoding)
+	return 1 + len(f.mimeType) + 1 + len(filename) + nullLength + len(description) + nullLength + len(f.data), nil
+}
+
+func (f GEOBFrame) Encoding() string {
+	return encodedbytes.EncodingForIndex(f.encoding)
+}
+
+func (f *GEOBFrame) SetEncoding(encoding string) error {
+	i := encodedbytes.IndexForEncoding(encoding)
+	if encodedbytes.EncodingForIndex(i) != encoding {
+		return errors.New("encoding: invalid encoding")
+	}
+
+	filenameDiff, err := encodedbytes.EncodedDiff(i, f.filename, f.encoding, f.filename)
+	if err != nil {
+		return err
+	}
+
+	descriptionDiff, err := encodedbytes.EncodedDiff(i, f.description, f.encoding, f.description)
+	if err != nil {
+		return err
+	}
+
+	newNullLength := encodedbytes.EncodingNullLengthForIndex(i)
+	oldNullLength := encodedbytes.EncodingNullLengthForIndex(f.encoding)
+
+	f.changeSize(filenameDiff + descriptionDiff + 2*(newNullLength-oldNullLength))
+	f.encoding = i
+	return nil
+}
+
+func (f GEOBFrame) MIMEType() string {
+	return f.mimeType
+}
+
+func (f *GEOBFrame) SetMIMEType(mimeType string) {
+	mimeType = strings.TrimRight(mimeType, "\x00")
+
+	f.changeSize(len(mimeType) - len(f.mimeType))
+	f.mimeType = mimeType
+}
+
+func (f GEOBFrame) Filename() string {
+	return f.filename
+}
+
+func (f *GEOBFrame) SetFilename(filename string) error {
+	diff, err := encodedbytes.EncodedDiff(f.encoding, filename, f.encoding, f.filename)
+	if err != nil {
+		return err
+	}
+
+	f.changeSize(diff)
+	f.filename = filename
+	return nil
+}
+
+func (f GEOBFrame) Description() string {
+	return f.description
+}
+
+func (f *GEOBFrame) SetDescription(description string) error {
+	diff, err := encodedbytes.EncodedDiff(f.encoding, description, f.encoding, f.description)
+	if err != nil {
+		return err
+	}
+
+	f.changeSize(diff)
+	f.description = description
+	return nil
+}
+
+func (f GEOBFrame) String() string {
+	return fmt.Sprintf("%s\t%s\t%s: <binary data>", f.mimeType, f.filename, f.description)
+}
+
+func (f GEOBFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteByte(f.encoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.mimeType, encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.filename, f.encoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.description, f.encoding); err != nil {
+		return bytes
+	}
+
+	if n, err := wr.Write(f.data); n < len(f.data) || err != nil {
+		return bytes
+	}
+
+	return bytes
+}
+
+// All embedded objects
+func (t Tag) Objects() []*GEOBFrame {
+	var objects []*GEOBFrame
+	for _, frame := range t.frames {
+		if object, ok := frame.(*GEOBFrame); ok {
+			objects = append(objects, object)
+		}
+	}
+
+	return objects
+}
+
+// Embedded object with a description, or nil if there is none
+func (t Tag) Object(description string) *GEOBFrame {
+	for _, object := range t.Objects() {
+		if object.Description() == description {
+			return object
+		}
+	}
+
+	return nil
+}
+
+// Data of the embedded object with a description
+func (t Tag) ExtractObject(description string) ([]byte, bool) {
+	if object := t.Object(description); object != nil {
+		return object.Data(), true
+	}
+
+	return nil, false
+}
+
+// Embeds an object, replacing the object with the same description
+func (t *Tag) AttachObject(mimeType, filename, description string, data []byte) error {
+	ft, ok := t.commonMap["Object"]
+	if !ok {
+		return errors.New("attach object: objects are not supported in this version")
+	}
+
+	object := NewGEOBFrame(ft, mimeType, filename, description, data)
+
+	// The object takes the place of the object it replaces
+	for i, frame := range t.frames {
+		if old, ok := frame.(*GEOBFrame); ok && old.Description() == description {
+			t.changeSize(-(t.frameHeaderSize + int(old.Size())))
+			old.setOwner(nil)
+			t.frames = append(t.frames[:i], t.frames[i+1:]...)
+			return t.InsertFrameAt(i, object)
+		}
+	}
+
+	t.AddFrames(object)
+	return nil
+}
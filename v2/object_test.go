@@ -0,0 +1,98 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGEOBFrame(t *testing.T) {
+	data := []byte("\x00application/octet-stream\x00wave.bin\x00Waveform\x00\x01\x02\x03")
+
+	frame, err := ReadGEOBFrame(FrameHead{FrameType: V23FrameTypeMap["GEOB"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*GEOBFrame)
+	if f.MIMEType() != "application/octet-stream" || f.Filename() != "wave.bin" || f.Description() != "Waveform" ||
+		!bytes.Equal(f.Data(), []byte{1, 2, 3}) {
+		t.Errorf("ReadGEOBFrame incorrect frame, %v", f)
+	}
+
+	if b := f.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	if err := f.SetEncoding("UTF-16"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.SetFilename("波形.bin"); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ReadGEOBFrame(f.FrameHead, f.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p := parsed.(*GEOBFrame); p.Filename() != "波形.bin" || p.Description() != "Waveform" || !bytes.Equal(p.Data(), []byte{1, 2, 3}) {
+		t.Errorf("UTF-16 frame incorrect, %v", p)
+	}
+}
+
+func TestGEOBFrameByteOrderMarks(t *testing.T) {
+	// A filename with only a byte order mark is written without it, and a
+	// description without one is written with it
+	data := []byte("\x01text/plain\x00\xff\xfe\x00\x00\x00D\x00\x00\x01\x02")
+	frame, err := ReadGEOBFrame(FrameHead{FrameType: V23FrameTypeMap["GEOB"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*GEOBFrame)
+	expected := []byte("\x01text/plain\x00\x00\x00\xff\xfeD\x00\x00\x00\x01\x02")
+	if b := f.Bytes(); int(f.Size()) != len(expected) || !bytes.Equal(b, expected) {
+		t.Errorf("Bytes produces %v, expected %v", b, expected)
+	}
+}
+
+func TestTagObjects(t *testing.T) {
+	for _, version := range []byte{2, 3, 4} {
+		tag := NewTag(version)
+		tag.AddFrames(NewTextFrame(tag.commonMap["Title"], "Title"))
+
+		if err := tag.AttachObject("application/x-waveform", "wave.bin", "Waveform", []byte{1, 2}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tag.AttachObject("application/json", "", "Analysis", []byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tag.AttachObject("application/x-waveform", "wave.bin", "Waveform", []byte{3, 4, 5}); err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := ReadTag(bytes.NewReader(tag.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		objects := parsed.Objects()
+		if len(objects) != 2 || objects[0].Description() != "Waveform" || objects[1].Description() != "Analysis" {
+			t.Fatalf("v2.%d incorrect objects %v", version, objects)
+		}
+
+		if data, ok := parsed.ExtractObject("Waveform"); !ok || !bytes.Equal(data, []byte{3, 4, 5}) {
+			t.Errorf("v2.%d incorrect object data %v", version, data)
+		}
+
+		if _, ok := parsed.ExtractObject("Missing"); ok {
+			t.Errorf("v2.%d extracted a missing object", version)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"sort"
+)
+
+var (
+	// Frames that come first in canonical order, with the IDs of each
+	// version at the same rank
+	canonicalLeadingFrames = [][]string{
+		{"TT2", "TIT2"},
+		{"TP1", "TPE1"},
+		{"TP2", "TPE2"},
+		{"TAL", "TALB"},
+		{"TRK", "TRCK"},
+		{"TPA", "TPOS"},
+		{"TYE", "TYER", "TDRC"},
+		{"TCO", "TCON"},
+	}
+
+	// Frames that come last in canonical order
+	canonicalTrailingFrames = [][]string{
+		{"PIC", "APIC"},
+	}
+
+	canonicalFrameRanks = make(map[string]int)
+)
+
+func init() {
+	for i, ids := range canonicalLeadingFrames {
+		for _, id := range ids {
+			canonicalFrameRanks[id] = i - len(canonicalLeadingFrames)
+		}
+	}
+
+	for i, ids := range canonicalTrailingFrames {
+		for _, id := range ids {
+			canonicalFrameRanks[id] = i + 1
+		}
+	}
+}
+
+// Orders frames canonically
+// Frames without a canonical rank come between the leading and trailing
+// frames, and frames of the same rank keep their order
+func canonicalFrameOrder(frames []Framer) []Framer {
+	ordered := make([]Framer, len(frames))
+	copy(ordered, frames)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return canonicalFrameRanks[ordered[i].Id()] < canonicalFrameRanks[ordered[j].Id()]
+	})
+
+	return ordered
+}
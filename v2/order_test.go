@@ -0,0 +1,78 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func frameIds(frames []Framer) []string {
+	ids := make([]string, len(frames))
+	for i, f := range frames {
+		ids[i] = f.Id()
+	}
+
+	return ids
+}
+
+func TestTagFrameOrder(t *testing.T) {
+	tag := NewTag(3)
+	tag.AddFrames(
+		NewTextFrame(V23FrameTypeMap["TCON"], "Pop"),
+		NewTextFrame(V23FrameTypeMap["TIT2"], "Nice Life"),
+		NewTextFrame(V23FrameTypeMap["TPE1"], "Michael Yang"),
+		NewTextFrame(V23FrameTypeMap["TALB"], "Nice Album"),
+	)
+
+	data := tag.Bytes()
+	for i := 0; i < 10; i++ {
+		if b := tag.Bytes(); !bytes.Equal(b, data) {
+			t.Fatal("Bytes is not deterministic")
+		}
+	}
+
+	parsed := ParseTag(bytes.NewReader(data))
+	if ids, expected := frameIds(parsed.AllFrames()), []string{"TCON", "TIT2", "TPE1", "TALB"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("ParseTag frame order %v, expected %v", ids, expected)
+	}
+
+	if err := parsed.MoveFrame(parsed.Frame("TCON"), 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := parsed.InsertFrameAt(1, NewTextFrame(V23FrameTypeMap["TRCK"], "1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids, expected := frameIds(parsed.AllFrames()), []string{"TIT2", "TRCK", "TPE1", "TALB", "TCON"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("frame order after moving and inserting %v, expected %v", ids, expected)
+	}
+
+	if err := parsed.MoveFrame(NewTextFrame(V23FrameTypeMap["TIT2"], "Nice Life"), 0); err == nil {
+		t.Error("MoveFrame did not return error for frame not in tag")
+	}
+
+	if err := parsed.InsertFrameAt(6, NewTextFrame(V23FrameTypeMap["TIT2"], "Nice Life")); err == nil {
+		t.Error("InsertFrameAt did not return error for index out of range")
+	}
+}
+
+func TestTagCanonicalOrder(t *testing.T) {
+	tag := NewTag(3)
+	tag.AddFrames(
+		NewDataFrame(V23FrameTypeMap["APIC"], []byte{0, 'i', 'm', 'a', 'g', 'e', '/', 'p', 'n', 'g', 0, 3, 0, 1, 2, 3}),
+		NewTextFrame(V23FrameTypeMap["TCOM"], "Composer"),
+		NewTextFrame(V23FrameTypeMap["TALB"], "Nice Album"),
+		NewTextFrame(V23FrameTypeMap["TIT2"], "Nice Life"),
+		NewTextFrame(V23FrameTypeMap["TPE1"], "Michael Yang"),
+	)
+	tag.SetCanonicalOrder(true)
+
+	parsed := ParseTag(bytes.NewReader(tag.Bytes()))
+	if ids, expected := frameIds(parsed.AllFrames()), []string{"TIT2", "TPE1", "TALB", "TCOM", "APIC"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("canonical frame order %v, expected %v", ids, expected)
+	}
+}
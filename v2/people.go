@@ -0,0 +1,249 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"strings"
+)
+
+// InvolvedPerson is a person and their role, such as a producer, or the
+// instrument of a musician
+type InvolvedPerson struct {
+	Role string
+	Name string
+}
+
+// Number of bytes of people in an encoding, with every role and name null
+// terminated
+func peopleSize(encoding byte, people []InvolvedPerson) (int, error) {
+	nullLength := encodedbytes.EncodingNullLengthForIndex(encoding)
+
+	size := 0
+	for _, person := range people {
+		for _, s := range []string{person.Role, person.Name} {
+			encoded, err := encodedbytes.Encoders[encoding].ConvertString(s)
+			if err != nil {
+				return 0, err
+			}
+
+			size += len(encoded) + nullLength
+		}
+	}
+
+	return size, nil
+}
+
+// InvolvedPeopleFrame represents frames that list people and their roles
+// These are the involved people lists of every version and the ID3v2.4
+// musician credits list
+type InvolvedPeopleFrame struct {
+	FrameHead
+	encoding byte
+	people   []InvolvedPerson
+}
+
+// Creates an involved people frame
+// The people are encoded as ISO-8859-1 when possible, and as UTF-16
+// otherwise, both of which are valid in every version
+func NewInvolvedPeopleFrame(ft FrameType, people []InvolvedPerson) *InvolvedPeopleFrame {
+	var encoding byte
+	size, err := peopleSize(encoding, people)
+	if err != nil {
+		encoding = encodedbytes.IndexForEncoding("UTF-16")
+		size, _ = peopleSize(encoding, people)
+	}
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(1 + size),
+	}
+
+	return &InvolvedPeopleFrame{head, encoding, append([]InvolvedPerson(nil), people...)}
+}
+
+func ParseInvolvedPeopleFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadInvolvedPeopleFrame(head, data))
+}
+
+func ReadInvolvedPeopleFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := &InvolvedPeopleFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
+	}
+
+	// Every string has its own terminator and, in UTF-16, its own byte order
+	// mark, but the terminator of the last string is often left out
+	var texts []string
+	for rd.Len() > 0 {
+		text, err := rd.ReadNullTermString(f.encoding)
+		if err != nil {
+			if text, err = rd.ReadRestString(f.encoding); err != nil {
+				return nil, err
+			}
+		}
+
+		texts = append(texts, text)
+	}
+
+	// A role without a name is kept with an empty name
+	for i := 0; i < len(texts); i += 2 {
+		person := InvolvedPerson{Role: texts[i]}
+		if i+1 < len(texts) {
+			person.Name = texts[i+1]
+		}
+
+		f.people = append(f.people, person)
+	}
+
+	size, err := peopleSize(f.encoding, f.people)
+	if err != nil {
+		return nil, err
+	}
+
+	f.size = uint32(1 + size)
+	return f, nil
+}
+
+func (f InvolvedPeopleFrame) Encoding() string {
+	return encodedbytes.EncodingForIndex(f.encoding)
+}
+
+func (f *InvolvedPeopleFrame) SetEncoding(encoding string) error {
+	i := encodedbytes.IndexForEncoding(encoding)
+	if encodedbytes.EncodingForIndex(i) != encoding {
+		return errors.New("encoding: invalid encoding")
+	}
+
+	size, err := peopleSize(i, f.people)
+	if err != nil {
+		return err
+	}
+
+	f.changeSize(1 + size - int(f.size))
+	f.encoding = i
+	return nil
+}
+
+// People in the order of the frame
+func (f InvolvedPeopleFrame) People() []InvolvedPerson {
+	return append([]InvolvedPerson(nil), f.people...)
+}
+
+func (f *InvolvedPeopleFrame) SetPeople(people []InvolvedPerson) error {
+	size, err := peopleSize(f.encoding, people)
+	if err != nil {
+		return err
+	}
+
+	f.changeSize(1 + size - int(f.size))
+	f.people = append([]InvolvedPerson(nil), people...)
+	return nil
+}
+
+// Adds a person after the people in the frame
+func (f *InvolvedPeopleFrame) AddPerson(role, name string) error {
+	return f.SetPeople(append(f.People(), InvolvedPerson{role, name}))
+}
+
+// Names of the people with a role
+func (f InvolvedPeopleFrame) Names(role string) []string {
+	var names []string
+	for _, person := range f.people {
+		if person.Role == role {
+			names = append(names, person.Name)
+		}
+	}
+
+	return names
+}
+
+func (f InvolvedPeopleFrame) String() string {
+	people := make([]string, len(f.people))
+	for i, person := range f.people {
+		people[i] = fmt.Sprintf("%s: %s", person.Role, person.Name)
+	}
+
+	return strings.Join(people, "\n")
+}
+
+func (f InvolvedPeopleFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteByte(f.encoding); err != nil {
+		return bytes
+	}
+
+	for _, person := range f.people {
+		if err = wr.WriteNullTermString(person.Role, f.encoding); err != nil {
+			return bytes
+		}
+
+		if err = wr.WriteNullTermString(person.Name, f.encoding); err != nil {
+			return bytes
+		}
+	}
+
+	return bytes
+}
+
+// People involved in the recording, such as producers and engineers
+// Before ID3v2.4, this includes the musicians
+func (t Tag) InvolvedPeople() []InvolvedPerson {
+	return t.people(t.commonMap["InvolvedPeople"])
+}
+
+func (t *Tag) SetInvolvedPeople(people []InvolvedPerson) error {
+	return t.setPeople(t.commonMap["InvolvedPeople"], people)
+}
+
+// Musicians and their instruments
+// Only ID3v2.4 has a musician credits list
+func (t Tag) MusicianCredits() []InvolvedPerson {
+	ft, ok := t.commonMap["MusicianCredits"]
+	if !ok {
+		return nil
+	}
+
+	return t.people(ft)
+}
+
+func (t *Tag) SetMusicianCredits(people []InvolvedPerson) error {
+	ft, ok := t.commonMap["MusicianCredits"]
+	if !ok {
+		return errors.New("set musician credits: musician credits are part of the involved people list in this version")
+	}
+
+	return t.setPeople(ft, people)
+}
+
+func (t Tag) people(ft FrameType) []InvolvedPerson {
+	if f, ok := t.Frame(ft.Id()).(*InvolvedPeopleFrame); ok {
+		return f.People()
+	}
+
+	return nil
+}
+
+// Replaces the people of a frame, deleting the frame when there are none
+func (t *Tag) setPeople(ft FrameType, people []InvolvedPerson) error {
+	if len(people) == 0 {
+		t.DeleteFrames(ft.Id())
+		return nil
+	}
+
+	if f, ok := t.Frame(ft.Id()).(*InvolvedPeopleFrame); ok {
+		return f.SetPeople(people)
+	}
+
+	t.AddFrames(NewInvolvedPeopleFrame(ft, people))
+	return nil
+}
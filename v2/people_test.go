@@ -0,0 +1,101 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestInvolvedPeopleFrame(t *testing.T) {
+	// Every UTF-16 string has a byte order mark, and the last terminator is
+	// left out
+	data := []byte{
+		1,
+		0xff, 0xfe, 'M', 0, 'i', 0, 'x', 0, 0, 0,
+		0xfe, 0xff, 0, 'J', 0, 'o', 0, 0,
+		0xff, 0xfe, 'E', 0, 'Q', 0, 0, 0,
+		0xff, 0xfe, 'R', 0, 0x7e, 0x00,
+	}
+
+	frame, err := ReadInvolvedPeopleFrame(FrameHead{FrameType: V23FrameTypeMap["IPLS"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*InvolvedPeopleFrame)
+	expected := []InvolvedPerson{{"Mix", "Jo"}, {"EQ", "R~"}}
+	if !reflect.DeepEqual(f.People(), expected) {
+		t.Errorf("ReadInvolvedPeopleFrame incorrect people %v", f.People())
+	}
+
+	parsed, err := ReadInvolvedPeopleFrame(f.FrameHead, f.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.Bytes()) != int(f.Size()) || !reflect.DeepEqual(parsed.(*InvolvedPeopleFrame).People(), expected) {
+		t.Errorf("Bytes does not round trip, %v", f.Bytes())
+	}
+
+	for _, encoding := range []string{"ISO-8859-1", "UTF-16BE", "UTF-8"} {
+		if err := f.SetEncoding(encoding); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := f.AddPerson("Producer", "Zoë"); err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := ReadInvolvedPeopleFrame(f.FrameHead, f.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if names := parsed.(*InvolvedPeopleFrame).Names("Producer"); len(names) == 0 || names[len(names)-1] != "Zoë" {
+			t.Errorf("%s frame has incorrect producers %v", encoding, names)
+		}
+	}
+}
+
+func TestConvertInvolvedPeople(t *testing.T) {
+	tag := NewTag(4)
+	tag.SetInvolvedPeople([]InvolvedPerson{{"producer", "A"}})
+	tag.SetMusicianCredits([]InvolvedPerson{{"violin", "B"}, {"cello", "C"}})
+
+	if err := tag.ConvertTo(3); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []InvolvedPerson{{"producer", "A"}, {"violin", "B"}, {"cello", "C"}}
+	if people := tag.InvolvedPeople(); !reflect.DeepEqual(people, expected) {
+		t.Errorf("ID3v2.3 tag has incorrect people %v", people)
+	}
+
+	if err := tag.SetMusicianCredits(expected); err == nil {
+		t.Errorf("SetMusicianCredits succeeded for ID3v2.3")
+	}
+
+	parsed, err := ReadTag(bytes.NewReader(tag.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := parsed.ConvertTo(4); err != nil {
+		t.Fatal(err)
+	}
+
+	if people := parsed.InvolvedPeople(); !reflect.DeepEqual(people, expected) {
+		t.Errorf("ID3v2.4 tag has incorrect people %v", people)
+	}
+
+	if err := parsed.ConvertTo(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if people := parsed.InvolvedPeople(); !reflect.DeepEqual(people, expected) {
+		t.Errorf("ID3v2.2 tag has incorrect people %v", people)
+	}
+}
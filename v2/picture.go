@@ -0,0 +1,224 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+const (
+	// Size of the image format of ID3v2.2 picture frames
+	pictureFormatSize = 3
+
+	// MIME type of pictures that are a URL to the image
+	pictureLink = "-->"
+)
+
+// PictureType is the type of an attached picture
+type PictureType byte
+
+const (
+	PictureOther PictureType = iota
+	PictureFileIcon
+	PictureOtherFileIcon
+	PictureCoverFront
+	PictureCoverBack
+	PictureLeafletPage
+	PictureMedia
+	PictureLeadArtist
+	PictureArtist
+	PictureConductor
+	PictureBand
+	PictureComposer
+	PictureLyricist
+	PictureRecordingLocation
+	PictureDuringRecording
+	PictureDuringPerformance
+	PictureScreenCapture
+	PictureBrightColoredFish
+	PictureIllustration
+	PictureBandLogotype
+	PicturePublisherLogotype
+)
+
+var (
+	pictureTypeDescriptions = [...]string{
+		"Other",
+		"32x32 pixels file icon",
+		"Other file icon",
+		"Cover (front)",
+		"Cover (back)",
+		"Leaflet page",
+		"Media",
+		"Lead artist/lead performer/soloist",
+		"Artist/performer",
+		"Conductor",
+		"Band/Orchestra",
+		"Composer",
+		"Lyricist/text writer",
+		"Recording Location",
+		"During recording",
+		"During performance",
+		"Movie/video screen capture",
+		"A bright coloured fish",
+		"Illustration",
+		"Band/artist logotype",
+		"Publisher/Studio logotype",
+	}
+
+	// Image formats of ID3v2.2 picture frames and their MIME types
+	pictureFormatMIMETypes = map[string]string{
+		"JPG": "image/jpeg",
+		"PNG": "image/png",
+		"GIF": "image/gif",
+		"BMP": "image/bmp",
+	}
+
+	// Signatures at the start of image data and their MIME types
+	imageSignatures = []struct {
+		signature []byte
+		mimeType  string
+	}{
+		{[]byte("\xff\xd8\xff"), "image/jpeg"},
+		{[]byte("\x89PNG\r\n\x1a\n"), "image/png"},
+		{[]byte("GIF87a"), "image/gif"},
+		{[]byte("GIF89a"), "image/gif"},
+		{[]byte("BM"), "image/bmp"},
+		{[]byte("II*\x00"), "image/tiff"},
+		{[]byte("MM\x00*"), "image/tiff"},
+	}
+)
+
+func (p PictureType) String() string {
+	if int(p) < len(pictureTypeDescriptions) {
+		return pictureTypeDescriptions[p]
+	}
+
+	return "Unknown"
+}
+
+// Detects the MIME type of image data from its first bytes
+// Unknown data gets "image/", which the specification treats as an image of
+// unspecified type
+func SniffMIMEType(data []byte) string {
+	for _, s := range imageSignatures {
+		if bytes.HasPrefix(data, s.signature) {
+			return s.mimeType
+		}
+	}
+
+	if len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP" {
+		return "image/webp"
+	}
+
+	return "image/"
+}
+
+// MIME type for the image format of an ID3v2.2 picture frame
+// Pictures that are links to images keep the "-->" format as MIME type
+func pictureFormatMIMEType(format string) string {
+	format = strings.ToUpper(format)
+	if mimeType, ok := pictureFormatMIMETypes[format]; ok {
+		return mimeType
+	}
+
+	if format == pictureLink {
+		return format
+	}
+
+	return "image/" + strings.ToLower(strings.TrimRight(format, " \x00"))
+}
+
+// Image format of an ID3v2.2 picture frame for a MIME type
+func pictureFormat(mimeType string) string {
+	for format, t := range pictureFormatMIMETypes {
+		if t == mimeType {
+			return format
+		}
+	}
+
+	format := strings.ToUpper(strings.TrimPrefix(mimeType, "image/"))
+	if len(format) < pictureFormatSize {
+		format += strings.Repeat(" ", pictureFormatSize-len(format))
+	}
+
+	return format[:pictureFormatSize]
+}
+
+// PictureFramer is implemented by frames that hold an attached picture
+type PictureFramer interface {
+	Framer
+	MIMEType() string
+	PictureType() PictureType
+	Description() string
+	Data() []byte
+}
+
+// All attached pictures
+func (t Tag) Pictures() []PictureFramer {
+	var pictures []PictureFramer
+	for _, frame := range t.frames {
+		if picture, ok := frame.(PictureFramer); ok {
+			pictures = append(pictures, picture)
+		}
+	}
+
+	return pictures
+}
+
+// First attached picture of a type, or nil if there is none
+func (t Tag) Picture(pictureType PictureType) PictureFramer {
+	for _, picture := range t.Pictures() {
+		if picture.PictureType() == pictureType {
+			return picture
+		}
+	}
+
+	return nil
+}
+
+// Replaces the attached pictures of a type with a picture
+// An empty MIME type is detected from the data
+func (t *Tag) SetPicture(pictureType PictureType, mimeType, description string, data []byte) error {
+	ft, ok := t.commonMap["Picture"]
+	if !ok {
+		return errors.New("set picture: pictures are not supported in this version")
+	}
+
+	if mimeType == "" {
+		mimeType = SniffMIMEType(data)
+	}
+
+	var picture Framer
+	if t.version == 2 {
+		picture = NewPictureFrame(ft, mimeType, pictureType, description, data)
+	} else {
+		picture = NewImageFrame(ft, mimeType, pictureType, description, data)
+	}
+
+	index := -1
+	for i := 0; i < len(t.frames); {
+		if p, ok := t.frames[i].(PictureFramer); ok && p.PictureType() == pictureType {
+			if index < 0 {
+				index = i
+			}
+
+			t.changeSize(-(t.frameHeaderSize + int(p.Size())))
+			p.setOwner(nil)
+			t.frames = append(t.frames[:i], t.frames[i+1:]...)
+			continue
+		}
+
+		i++
+	}
+
+	// The picture takes the place of the first picture it replaces
+	if index < 0 {
+		index = len(t.frames)
+	}
+
+	return t.InsertFrameAt(index, picture)
+}
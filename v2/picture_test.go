@@ -0,0 +1,136 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"testing"
+)
+
+var pngData = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+func TestImageFrame(t *testing.T) {
+	frame := NewImageFrame(V23FrameTypeMap["APIC"], "image/png", PictureCoverFront, "Café", pngData)
+
+	data := frame.Bytes()
+	if len(data) != int(frame.Size()) {
+		t.Errorf("NewImageFrame size %d, expected %d", frame.Size(), len(data))
+	}
+
+	parsed, err := ReadImageFrame(frame.FrameHead, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := parsed.(*ImageFrame)
+	if s := image.MIMEType(); s != "image/png" {
+		t.Errorf("ReadImageFrame incorrect MIME type, %v", s)
+	}
+
+	if p := image.PictureType(); p != PictureCoverFront {
+		t.Errorf("ReadImageFrame incorrect picture type, %v", p)
+	}
+
+	if s := image.Description(); s != "Café" {
+		t.Errorf("ReadImageFrame incorrect description, %v", s)
+	}
+
+	if !bytes.Equal(image.Data(), pngData) {
+		t.Errorf("ReadImageFrame incorrect data, %v", image.Data())
+	}
+
+	image.SetMIMEType("")
+	if err := image.SetDescription("Cover ☺"); err == nil {
+		t.Error("SetDescription did not return error for unrepresentable description")
+	}
+
+	if err := image.SetDescription("Cover"); err != nil {
+		t.Fatal(err)
+	}
+
+	if data := image.Bytes(); len(data) != int(image.Size()) {
+		t.Errorf("ImageFrame size %d, expected %d", image.Size(), len(data))
+	}
+}
+
+func TestImageFrameByteOrderMarkDescription(t *testing.T) {
+	// A UTF-16 description with only a byte order mark is written without it
+	data := []byte("\x01image/jpeg\x00\x03\xff\xfe\x00\x00\xff\xd8\xff\xd9")
+	frame, err := ReadImageFrame(FrameHead{FrameType: V23FrameTypeMap["APIC"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := frame.(*ImageFrame)
+	expected := []byte("\x01image/jpeg\x00\x03\x00\x00\xff\xd8\xff\xd9")
+	if b := image.Bytes(); int(image.Size()) != len(expected) || !bytes.Equal(b, expected) {
+		t.Errorf("Bytes produces %v, expected %v", b, expected)
+	}
+
+	parsed, err := ReadImageFrame(image.FrameHead, image.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := parsed.(*ImageFrame).Data(); !bytes.Equal(d, []byte{0xff, 0xd8, 0xff, 0xd9}) {
+		t.Errorf("ReadImageFrame incorrect data after round trip, %v", d)
+	}
+}
+
+func TestSniffMIMEType(t *testing.T) {
+	types := map[string]string{
+		"\xff\xd8\xff\xe0\x00\x10JFIF": "image/jpeg",
+		string(pngData):                "image/png",
+		"GIF89a\x01\x00":               "image/gif",
+		"RIFF\x00\x00\x00\x00WEBPVP8 ": "image/webp",
+		"not an image":                 "image/",
+	}
+
+	for data, expected := range types {
+		if mimeType := SniffMIMEType([]byte(data)); mimeType != expected {
+			t.Errorf("SniffMIMEType(%q) = %v, expected %v", data, mimeType, expected)
+		}
+	}
+}
+
+func TestTagSetPicture(t *testing.T) {
+	tag := NewTag(3)
+	tag.SetTitle("Nice Life")
+
+	if err := tag.SetPicture(PictureCoverFront, "", "Front", []byte("\xff\xd8\xff")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tag.SetPicture(PictureCoverBack, "", "Back", pngData); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tag.SetPicture(PictureCoverFront, "", "New front", pngData); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := ParseTag(bytes.NewReader(tag.Bytes()))
+
+	pictures := parsed.Pictures()
+	if len(pictures) != 2 {
+		t.Fatalf("SetPicture did not replace picture, %d pictures", len(pictures))
+	}
+
+	front := parsed.Picture(PictureCoverFront)
+	if front == nil || front != pictures[0] {
+		t.Fatal("SetPicture did not keep the place of the replaced picture")
+	}
+
+	if s := front.Description(); s != "New front" {
+		t.Errorf("SetPicture incorrect description, %v", s)
+	}
+
+	if s := front.MIMEType(); s != "image/png" {
+		t.Errorf("SetPicture incorrect MIME type, %v", s)
+	}
+
+	if parsed.Picture(PictureArtist) != nil {
+		t.Error("Picture returned picture of wrong type")
+	}
+}
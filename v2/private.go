@@ -0,0 +1,114 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"strings"
+)
+
+// PrivateFrame represents frames with data that is only meaningful to the
+// owner, which is usually identified by a URL or an email address
+type PrivateFrame struct {
+	DataFrame
+	ownerIdentifier string
+}
+
+func NewPrivateFrame(ft FrameType, ownerId string, data []byte) *PrivateFrame {
+	ownerId = strings.TrimRight(ownerId, "\x00")
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(len(ownerId) + 1 + len(data)),
+	}
+
+	return &PrivateFrame{
+		DataFrame:       DataFrame{head, data},
+		ownerIdentifier: ownerId,
+	}
+}
+
+func ParsePrivateFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadPrivateFrame(head, data))
+}
+
+func ReadPrivateFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := new(PrivateFrame)
+	f.FrameHead = head
+	rd := encodedbytes.NewReader(data)
+
+	if f.ownerIdentifier, err = rd.ReadNullTermString(encodedbytes.NativeEncoding); err != nil {
+		return nil, err
+	}
+
+	if f.data, err = rd.ReadRest(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f PrivateFrame) OwnerIdentifier() string {
+	return f.ownerIdentifier
+}
+
+func (f *PrivateFrame) SetOwnerIdentifier(ownerId string) {
+	ownerId = strings.TrimRight(ownerId, "\x00")
+
+	f.changeSize(len(ownerId) - len(f.ownerIdentifier))
+	f.ownerIdentifier = ownerId
+}
+
+func (f PrivateFrame) String() string {
+	return fmt.Sprintf("%s: <binary data>", f.ownerIdentifier)
+}
+
+func (f PrivateFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteNullTermString(f.ownerIdentifier, encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if n, err := wr.Write(f.data); n < len(f.data) || err != nil {
+		return bytes
+	}
+
+	return bytes
+}
+
+// Data of the private frame of an owner
+func (t Tag) PrivateData(ownerId string) ([]byte, bool) {
+	for _, frame := range t.frames {
+		if f, ok := frame.(*PrivateFrame); ok && f.OwnerIdentifier() == ownerId {
+			return f.Data(), true
+		}
+	}
+
+	return nil, false
+}
+
+// Sets the data of the private frame of an owner, adding a frame if the owner
+// has none
+func (t *Tag) SetPrivateData(ownerId string, data []byte) error {
+	ft, ok := t.commonMap["Private"]
+	if !ok {
+		return errors.New("set private data: private frames are not supported in this version")
+	}
+
+	for _, frame := range t.frames {
+		if f, ok := frame.(*PrivateFrame); ok && f.OwnerIdentifier() == ownerId {
+			f.SetData(data)
+			return nil
+		}
+	}
+
+	t.AddFrames(NewPrivateFrame(ft, ownerId, data))
+	return nil
+}
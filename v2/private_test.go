@@ -0,0 +1,60 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrivateFrame(t *testing.T) {
+	data := []byte("WM/MediaClassPrimaryID\x00\xbc\x7d\x60\xd1")
+
+	frame, err := ReadPrivateFrame(FrameHead{FrameType: V23FrameTypeMap["PRIV"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*PrivateFrame)
+	if f.OwnerIdentifier() != "WM/MediaClassPrimaryID" || !bytes.Equal(f.Data(), []byte{0xbc, 0x7d, 0x60, 0xd1}) {
+		t.Errorf("ReadPrivateFrame incorrect frame, %v", f)
+	}
+
+	if b := f.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	f.SetOwnerIdentifier("o")
+	if b := f.Bytes(); len(b) != int(f.Size()) || !bytes.Equal(b, []byte{'o', 0, 0xbc, 0x7d, 0x60, 0xd1}) {
+		t.Errorf("SetOwnerIdentifier produces %v", b)
+	}
+}
+
+func TestTagPrivateData(t *testing.T) {
+	tag := NewTag(4)
+	tag.SetPrivateData("www.traktor.com", []byte{1})
+	tag.SetPrivateData("Serato", []byte{2})
+	tag.SetPrivateData("www.traktor.com", []byte{3, 4})
+
+	parsed, err := ReadTag(bytes.NewReader(tag.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(parsed.Frames("PRIV")); n != 2 {
+		t.Errorf("SetPrivateData added %d private frames", n)
+	}
+
+	if data, ok := parsed.PrivateData("www.traktor.com"); !ok || !bytes.Equal(data, []byte{3, 4}) {
+		t.Errorf("incorrect private data %v", data)
+	}
+
+	if _, ok := parsed.PrivateData("Missing"); ok {
+		t.Errorf("PrivateData found data of a missing owner")
+	}
+
+	if err := NewTag(2).SetPrivateData("o", nil); err == nil {
+		t.Errorf("SetPrivateData succeeded for ID3v2.2")
+	}
+}
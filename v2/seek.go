@@ -0,0 +1,320 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// Size of the fixed fields of an MPEG location lookup table frame
+	locationTableHeaderSize = 2 + 3 + 3 + 1 + 1
+
+	// Deviations are kept in at most 32 bits
+	maxDeviationBits = 32
+
+	// Size of the offset of a seek frame
+	seekOffsetSize = 4
+)
+
+// LocationDeviation is how far a reference point of an MPEG location lookup
+// table is from where the previous point and the distances between
+// references put it
+type LocationDeviation struct {
+	Bytes        uint32
+	Milliseconds uint32
+}
+
+// LocationTable is an MPEG location lookup table, which has reference points
+// at regular numbers of MPEG frames from the start of the audio
+type LocationTable struct {
+	FramesBetween       uint16
+	BytesBetween        uint32
+	MillisecondsBetween uint32
+
+	// Number of bits of each deviation
+	BytesDeviationBits        byte
+	MillisecondsDeviationBits byte
+
+	Deviations []LocationDeviation
+}
+
+func (t LocationTable) validate() error {
+	if t.BytesBetween >= 1<<24 || t.MillisecondsBetween >= 1<<24 {
+		return errors.New("location table: distance between references does not fit in 24 bits")
+	}
+
+	if t.BytesDeviationBits > maxDeviationBits || t.MillisecondsDeviationBits > maxDeviationBits {
+		return fmt.Errorf("location table: deviations of more than %d bits are not supported", maxDeviationBits)
+	}
+
+	// References have to end on a nibble
+	if (t.BytesDeviationBits+t.MillisecondsDeviationBits)%4 != 0 {
+		return errors.New("location table: deviation bits are not a multiple of 4")
+	}
+
+	for _, d := range t.Deviations {
+		if !fitsBits(uint64(d.Bytes), t.BytesDeviationBits) || !fitsBits(uint64(d.Milliseconds), t.MillisecondsDeviationBits) {
+			return fmt.Errorf("location table: deviation %v does not fit in its bits", d)
+		}
+	}
+
+	return nil
+}
+
+func (t LocationTable) size() int {
+	bits := len(t.Deviations) * int(t.BytesDeviationBits+t.MillisecondsDeviationBits)
+	return locationTableHeaderSize + (bits+7)/8
+}
+
+// Byte offset in the audio of the last reference point at or before a time in
+// milliseconds, along with the time of the point
+// Playback from the point is in sync with the MPEG frames
+func (t LocationTable) ByteOffset(milliseconds uint64) (offset, at uint64) {
+	for _, d := range t.Deviations {
+		nextOffset := offset + uint64(t.BytesBetween) + uint64(d.Bytes)
+		nextAt := at + uint64(t.MillisecondsBetween) + uint64(d.Milliseconds)
+		if nextAt > milliseconds {
+			break
+		}
+
+		offset, at = nextOffset, nextAt
+	}
+
+	return offset, at
+}
+
+// MLLTFrame represents MPEG location lookup table frames
+type MLLTFrame struct {
+	FrameHead
+	table LocationTable
+}
+
+func NewMLLTFrame(ft FrameType, table LocationTable) (*MLLTFrame, error) {
+	f := &MLLTFrame{FrameHead: FrameHead{FrameType: ft}}
+	if err := f.SetTable(table); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func ParseMLLTFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadMLLTFrame(head, data))
+}
+
+func ReadMLLTFrame(head FrameHead, data []byte) (Framer, error) {
+	if len(data) < locationTableHeaderSize {
+		return nil, truncatedError(len(data), locationTableHeaderSize)
+	}
+
+	t := LocationTable{
+		FramesBetween:             binary.BigEndian.Uint16(data[0:2]),
+		BytesBetween:              uint32(data[2])<<16 | uint32(data[3])<<8 | uint32(data[4]),
+		MillisecondsBetween:       uint32(data[5])<<16 | uint32(data[6])<<8 | uint32(data[7]),
+		BytesDeviationBits:        data[8],
+		MillisecondsDeviationBits: data[9],
+	}
+
+	if t.BytesDeviationBits > maxDeviationBits || t.MillisecondsDeviationBits > maxDeviationBits {
+		return nil, fmt.Errorf("location table: deviations of more than %d bits are not supported", maxDeviationBits)
+	}
+
+	// The references are packed together, and the last is padded to a byte
+	rd := bitReader{data: data[locationTableHeaderSize:]}
+	referenceBits := int(t.BytesDeviationBits + t.MillisecondsDeviationBits)
+	if referenceBits > 0 {
+		for rd.remaining() >= referenceBits {
+			d := LocationDeviation{
+				Bytes:        rd.read(t.BytesDeviationBits),
+				Milliseconds: rd.read(t.MillisecondsDeviationBits),
+			}
+
+			t.Deviations = append(t.Deviations, d)
+		}
+	}
+
+	f := &MLLTFrame{head, t}
+	f.size = uint32(t.size())
+	return f, nil
+}
+
+func (f MLLTFrame) Table() LocationTable {
+	t := f.table
+	t.Deviations = append([]LocationDeviation(nil), t.Deviations...)
+	return t
+}
+
+func (f *MLLTFrame) SetTable(table LocationTable) error {
+	if err := table.validate(); err != nil {
+		return err
+	}
+
+	table.Deviations = append([]LocationDeviation(nil), table.Deviations...)
+	f.changeSize(table.size() - int(f.size))
+	f.table = table
+	return nil
+}
+
+// Byte offset in the audio of the last reference point at or before a time in
+// milliseconds, along with the time of the point
+func (f MLLTFrame) ByteOffset(milliseconds uint64) (offset, at uint64) {
+	return f.table.ByteOffset(milliseconds)
+}
+
+func (f MLLTFrame) String() string {
+	return fmt.Sprintf("%d references every %d frames, %d bytes, %d ms",
+		len(f.table.Deviations), f.table.FramesBetween, f.table.BytesBetween, f.table.MillisecondsBetween)
+}
+
+func (f MLLTFrame) Bytes() []byte {
+	t := f.table
+	bytes := make([]byte, locationTableHeaderSize, f.Size())
+
+	binary.BigEndian.PutUint16(bytes[0:2], t.FramesBetween)
+	copy(bytes[2:5], uint24Bytes(t.BytesBetween))
+	copy(bytes[5:8], uint24Bytes(t.MillisecondsBetween))
+	bytes[8] = t.BytesDeviationBits
+	bytes[9] = t.MillisecondsDeviationBits
+
+	wr := bitWriter{data: bytes}
+	for _, d := range t.Deviations {
+		wr.write(d.Bytes, t.BytesDeviationBits)
+		wr.write(d.Milliseconds, t.MillisecondsDeviationBits)
+	}
+
+	return wr.data
+}
+
+func uint24Bytes(n uint32) []byte {
+	return []byte{byte(n >> 16), byte(n >> 8), byte(n)}
+}
+
+// Reads big endian values that are packed without regard to byte boundaries
+type bitReader struct {
+	data []byte
+	bit  int
+}
+
+func (r bitReader) remaining() int {
+	return len(r.data)*8 - r.bit
+}
+
+func (r *bitReader) read(bits byte) uint32 {
+	var v uint32
+	for i := byte(0); i < bits; i++ {
+		b := r.data[r.bit/8] >> (7 - uint(r.bit%8)) & 1
+		v = v<<1 | uint32(b)
+		r.bit++
+	}
+
+	return v
+}
+
+// Writes big endian values that are packed without regard to byte boundaries
+type bitWriter struct {
+	data []byte
+	bit  int
+}
+
+func (w *bitWriter) write(v uint32, bits byte) {
+	for i := int(bits) - 1; i >= 0; i-- {
+		if w.bit%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+
+		b := byte(v>>uint(i)) & 1
+		w.data[len(w.data)-1] |= b << (7 - uint(w.bit%8))
+		w.bit++
+	}
+}
+
+// SeekFrame represents ID3v2.4 seek frames, which point to another tag
+// further in the file
+type SeekFrame struct {
+	FrameHead
+	offset uint32
+}
+
+func NewSeekFrame(ft FrameType, offset uint32) *SeekFrame {
+	head := FrameHead{
+		FrameType: ft,
+		size:      seekOffsetSize,
+	}
+
+	return &SeekFrame{head, offset}
+}
+
+func ParseSeekFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadSeekFrame(head, data))
+}
+
+func ReadSeekFrame(head FrameHead, data []byte) (Framer, error) {
+	if len(data) < seekOffsetSize {
+		return nil, truncatedError(len(data), seekOffsetSize)
+	}
+
+	return &SeekFrame{head, binary.BigEndian.Uint32(data)}, nil
+}
+
+// Minimum offset from the end of the tag to the start of the next tag
+func (f SeekFrame) Offset() uint32 {
+	return f.offset
+}
+
+func (f *SeekFrame) SetOffset(offset uint32) {
+	f.offset = offset
+	f.changeSize(0)
+}
+
+func (f SeekFrame) String() string {
+	return fmt.Sprintf("%d", f.offset)
+}
+
+func (f SeekFrame) Bytes() []byte {
+	bytes := make([]byte, f.Size())
+	binary.BigEndian.PutUint32(bytes, f.offset)
+	return bytes
+}
+
+// Offset from the end of the tag to the next tag, if the tag has a seek frame
+func (t Tag) SeekOffset() (uint32, bool) {
+	if f, ok := t.Frame("SEEK").(*SeekFrame); ok && t.version == 4 {
+		return f.Offset(), true
+	}
+
+	return 0, false
+}
+
+// Follows the seek frames of a tag to the tags after it
+// The end is the offset in the file just after the tag, including its footer
+func ReadSeekTags(readSeeker io.ReadSeeker, tag *Tag, end int64) ([]*Tag, error) {
+	var tags []*Tag
+
+	for {
+		offset, ok := tag.SeekOffset()
+		if !ok {
+			return tags, nil
+		}
+
+		start := end + int64(offset)
+		if _, err := readSeeker.Seek(start, os.SEEK_SET); err != nil {
+			return tags, err
+		}
+
+		next, err := ReadTag(readSeeker)
+		if err != nil {
+			return tags, err
+		}
+
+		// Tags only point forward, so following them always ends
+		tag = next
+		end = start + int64(HeaderSize+next.Header.Size()+next.footerSize())
+		tags = append(tags, next)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestMLLTFrame(t *testing.T) {
+	// Deviations of 6 bits each are packed in three bytes, and the last byte
+	// is too short for another reference
+	data := []byte{
+		0, 10, 0, 0x10, 0, 0, 0, 0xfa, 6, 6,
+		0x10, 0x8f, 0xc1, 0x40,
+	}
+
+	frame, err := ReadMLLTFrame(FrameHead{FrameType: V23FrameTypeMap["MLLT"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*MLLTFrame)
+	expected := LocationTable{
+		FramesBetween:             10,
+		BytesBetween:              4096,
+		MillisecondsBetween:       250,
+		BytesDeviationBits:        6,
+		MillisecondsDeviationBits: 6,
+		Deviations:                []LocationDeviation{{4, 8}, {63, 1}},
+	}
+
+	if !reflect.DeepEqual(f.Table(), expected) {
+		t.Errorf("ReadMLLTFrame incorrect table %v", f.Table())
+	}
+
+	if b := f.Bytes(); !bytes.Equal(b, data[:13]) || int(f.Size()) != 13 {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data[:13], b)
+	}
+
+	for _, c := range []struct {
+		milliseconds, offset, at uint64
+	}{
+		{0, 0, 0},
+		{257, 0, 0},
+		{258, 4100, 258},
+		{1000, 8259, 509},
+	} {
+		if offset, at := f.ByteOffset(c.milliseconds); offset != c.offset || at != c.at {
+			t.Errorf("ByteOffset(%d) = %d, %d, expected %d, %d", c.milliseconds, offset, at, c.offset, c.at)
+		}
+	}
+
+	expected.BytesDeviationBits = 5
+	if err := f.SetTable(expected); err == nil {
+		t.Errorf("SetTable accepted deviation bits that are not a multiple of 4")
+	}
+}
+
+func TestReadSeekTags(t *testing.T) {
+	audio := []byte{0xff, 0xfb, 0x90, 0x00, 0x00}
+
+	first := NewTag(4)
+	first.SetTitle("First")
+	first.AddFrames(NewSeekFrame(V24FrameTypeMap["SEEK"], uint32(len(audio))))
+
+	appended := NewTag(4)
+	appended.SetFooter(true)
+	appended.SetTitle("Appended")
+
+	var file []byte
+	file = append(file, first.Bytes()...)
+	end := int64(len(file))
+	file = append(file, audio...)
+	file = append(file, appended.Bytes()...)
+
+	tag, err := ReadTag(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if offset, ok := tag.SeekOffset(); !ok || offset != uint32(len(audio)) {
+		t.Errorf("incorrect seek offset %d", offset)
+	}
+
+	tags, err := ReadSeekTags(bytes.NewReader(file), tag, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tags) != 1 || tags[0].Title() != "Appended" {
+		t.Errorf("ReadSeekTags incorrect tags %v", tags)
+	}
+}
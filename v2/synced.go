@@ -0,0 +1,548 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TimestampFormat is the unit of the timestamps of synchronized frames
+type TimestampFormat byte
+
+const (
+	TimestampMPEGFrames   TimestampFormat = 1
+	TimestampMilliseconds TimestampFormat = 2
+)
+
+// LyricsContentType is the type of text in a synchronized lyrics frame
+type LyricsContentType byte
+
+const (
+	LyricsOther LyricsContentType = iota
+	LyricsText
+	LyricsTranscription
+	LyricsMovement
+	LyricsEvents
+	LyricsChord
+	LyricsTrivia
+	LyricsWebpageURLs
+	LyricsImageURLs
+)
+
+const (
+	// Size of the timestamps of synchronized frames
+	timestampSize = 4
+
+	// Tempos from this value on take a second byte
+	tempoExtended = 0xff
+	maxTempo      = 2 * tempoExtended
+)
+
+var (
+	errNotMilliseconds = errors.New("lrc: timestamps are not in milliseconds")
+
+	lrcTimestamp = regexp.MustCompile(`^\[(\d+):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+	lrcOffset    = regexp.MustCompile(`^\[offset:\s*([+-]?\d+)\s*\]$`)
+)
+
+func readTimestamp(rd *encodedbytes.Reader) (uint32, error) {
+	b, err := rd.ReadNumBytes(timestampSize)
+	if err != nil {
+		return 0, truncatedError(rd.Len(), timestampSize)
+	}
+
+	return encodedbytes.NormInt(b)
+}
+
+// SyncedText is text that is shown at a point in time
+type SyncedText struct {
+	Time uint32
+	Text string
+}
+
+// SyncLyricsFrame represents frames that contain text synchronized with the
+// audio, such as lyrics for karaoke
+type SyncLyricsFrame struct {
+	FrameHead
+	encoding    byte
+	language    string
+	format      TimestampFormat
+	contentType LyricsContentType
+	descriptor  string
+	entries     []SyncedText
+}
+
+// Creates a synchronized lyrics frame with timestamps in milliseconds
+// The text is encoded as ISO-8859-1 when possible, and as UTF-16 otherwise
+func NewSyncLyricsFrame(ft FrameType, language, descriptor string, entries []SyncedText) (*SyncLyricsFrame, error) {
+	if len(language) != 3 {
+		return nil, errors.New("language: invalid language string")
+	}
+
+	f := &SyncLyricsFrame{
+		FrameHead:   FrameHead{FrameType: ft},
+		language:    language,
+		format:      TimestampMilliseconds,
+		contentType: LyricsText,
+		descriptor:  descriptor,
+		entries:     append([]SyncedText(nil), entries...),
+	}
+
+	size, err := f.dataSize()
+	if err != nil {
+		f.encoding = encodedbytes.IndexForEncoding("UTF-16")
+		if size, err = f.dataSize(); err != nil {
+			return nil, err
+		}
+	}
+
+	f.size = uint32(size)
+	return f, nil
+}
+
+func ParseSyncLyricsFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadSyncLyricsFrame(head, data))
+}
+
+func ReadSyncLyricsFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := &SyncLyricsFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
+	}
+
+	if f.language, err = rd.ReadNumBytesString(3); err != nil {
+		return nil, truncatedError(len(data), 1+3+2)
+	}
+
+	format, err := rd.ReadByte()
+	if err != nil {
+		return nil, truncatedError(len(data), 1+3+2)
+	}
+
+	contentType, err := rd.ReadByte()
+	if err != nil {
+		return nil, truncatedError(len(data), 1+3+2)
+	}
+
+	f.format = TimestampFormat(format)
+	f.contentType = LyricsContentType(contentType)
+
+	if f.descriptor, err = rd.ReadNullTermString(f.encoding); err != nil {
+		return nil, err
+	}
+
+	for rd.Len() > 0 {
+		var entry SyncedText
+		if entry.Text, err = rd.ReadNullTermString(f.encoding); err != nil {
+			return nil, err
+		}
+
+		if entry.Time, err = readTimestamp(rd); err != nil {
+			return nil, err
+		}
+
+		f.entries = append(f.entries, entry)
+	}
+
+	// Text is written with its byte order marks as they are encoded, which
+	// need not match how they were read
+	size, err := f.dataSize()
+	if err != nil {
+		return nil, err
+	}
+
+	f.size = uint32(size)
+	return f, nil
+}
+
+// Number of bytes of the frame data
+func (f SyncLyricsFrame) dataSize() (int, error) {
+	nullLength := encodedbytes.EncodingNullLengthForIndex(f.encoding)
+
+	descriptor, err := encodedbytes.Encoders[f.encoding].ConvertString(f.descriptor)
+	if err != nil {
+		return 0, err
+	}
+
+	size := 1 + 3 + 1 + 1 + len(descriptor) + nullLength
+	for _, entry := range f.entries {
+		text, err := encodedbytes.Encoders[f.encoding].ConvertString(entry.Text)
+		if err != nil {
+			return 0, err
+		}
+
+		size += len(text) + nullLength + timestampSize
+	}
+
+	return size, nil
+}
+
+// Applies a change to the frame if its contents can still be encoded
+func (f *SyncLyricsFrame) change(update func(*SyncLyricsFrame)) error {
+	changed := *f
+	update(&changed)
+
+	size, err := changed.dataSize()
+	if err != nil {
+		return err
+	}
+
+	update(f)
+	f.changeSize(size - int(f.size))
+	return nil
+}
+
+func (f SyncLyricsFrame) Encoding() string {
+	return encodedbytes.EncodingForIndex(f.encoding)
+}
+
+func (f *SyncLyricsFrame) SetEncoding(encoding string) error {
+	i := encodedbytes.IndexForEncoding(encoding)
+	if encodedbytes.EncodingForIndex(i) != encoding {
+		return errors.New("encoding: invalid encoding")
+	}
+
+	return f.change(func(f *SyncLyricsFrame) { f.encoding = i })
+}
+
+func (f SyncLyricsFrame) Language() string {
+	return f.language
+}
+
+func (f *SyncLyricsFrame) SetLanguage(language string) error {
+	if len(language) != 3 {
+		return errors.New("language: invalid language string")
+	}
+
+	f.language = language
+	f.changeSize(0)
+	return nil
+}
+
+func (f SyncLyricsFrame) TimestampFormat() TimestampFormat {
+	return f.format
+}
+
+// Sets the unit of the timestamps
+// The timestamps themselves are not converted
+func (f *SyncLyricsFrame) SetTimestampFormat(format TimestampFormat) {
+	f.format = format
+	f.changeSize(0)
+}
+
+func (f SyncLyricsFrame) ContentType() LyricsContentType {
+	return f.contentType
+}
+
+func (f *SyncLyricsFrame) SetContentType(contentType LyricsContentType) {
+	f.contentType = contentType
+	f.changeSize(0)
+}
+
+func (f SyncLyricsFrame) Descriptor() string {
+	return f.descriptor
+}
+
+func (f *SyncLyricsFrame) SetDescriptor(descriptor string) error {
+	return f.change(func(f *SyncLyricsFrame) { f.descriptor = descriptor })
+}
+
+// Synchronized text in the order of the frame
+func (f SyncLyricsFrame) Entries() []SyncedText {
+	return append([]SyncedText(nil), f.entries...)
+}
+
+func (f *SyncLyricsFrame) SetEntries(entries []SyncedText) error {
+	entries = append([]SyncedText(nil), entries...)
+	return f.change(func(f *SyncLyricsFrame) { f.entries = entries })
+}
+
+// Synchronized text as LRC lyrics
+// Only timestamps in milliseconds can be converted
+func (f SyncLyricsFrame) LRC() (string, error) {
+	if f.format != TimestampMilliseconds {
+		return "", errNotMilliseconds
+	}
+
+	return FormatLRC(f.entries), nil
+}
+
+func (f SyncLyricsFrame) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\t%s:", f.language, f.descriptor)
+	for _, entry := range f.entries {
+		fmt.Fprintf(&b, "\n%d\t%s", entry.Time, entry.Text)
+	}
+
+	return b.String()
+}
+
+func (f SyncLyricsFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteByte(f.encoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteString(f.language, encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteByte(byte(f.format)); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteByte(byte(f.contentType)); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.descriptor, f.encoding); err != nil {
+		return bytes
+	}
+
+	for _, entry := range f.entries {
+		if err = wr.WriteNullTermString(entry.Text, f.encoding); err != nil {
+			return bytes
+		}
+
+		if _, err = wr.Write(encodedbytes.NormBytes(entry.Time)); err != nil {
+			return bytes
+		}
+	}
+
+	return bytes
+}
+
+// Parses LRC lyrics into synchronized text with timestamps in milliseconds
+// Lines with several timestamps are repeated at each of them, and the text is
+// ordered by time. ID tags other than the offset are ignored.
+func ParseLRC(lrc string) ([]SyncedText, error) {
+	var entries []SyncedText
+	var offset int64
+
+	for n, line := range strings.Split(lrc, "\n") {
+		line = strings.TrimSpace(line)
+
+		if m := lrcOffset.FindStringSubmatch(line); m != nil {
+			offset, _ = strconv.ParseInt(m[1], 10, 32)
+			continue
+		}
+
+		var times []int64
+		for {
+			m := lrcTimestamp.FindStringSubmatch(line)
+			if m == nil {
+				break
+			}
+
+			minutes, _ := strconv.ParseInt(m[1], 10, 32)
+			seconds, _ := strconv.ParseInt(m[2], 10, 32)
+			if seconds >= 60 {
+				return nil, fmt.Errorf("lrc: line %d: invalid timestamp %s", n+1, m[0])
+			}
+
+			// Fractions of a second are padded to milliseconds
+			fraction := m[3] + strings.Repeat("0", 3-len(m[3]))
+			millis, _ := strconv.ParseInt(fraction, 10, 32)
+
+			times = append(times, (minutes*60+seconds)*1000+millis)
+			line = line[len(m[0]):]
+		}
+
+		for _, t := range times {
+			// A positive offset shows the text earlier
+			t -= offset
+			if t < 0 {
+				t = 0
+			}
+
+			entries = append(entries, SyncedText{Time: uint32(t), Text: line})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time < entries[j].Time
+	})
+
+	return entries, nil
+}
+
+// Formats synchronized text with timestamps in milliseconds as LRC lyrics
+// Timestamps are written in hundredths of a second, and line breaks in the
+// text are removed
+func FormatLRC(entries []SyncedText) string {
+	var b strings.Builder
+	for _, entry := range entries {
+		hundredths := entry.Time / 10
+		text := strings.Join(strings.Fields(strings.ReplaceAll(entry.Text, "\n", " ")), " ")
+		fmt.Fprintf(&b, "[%02d:%02d.%02d]%s\n", hundredths/6000, hundredths/100%60, hundredths%100, text)
+	}
+
+	return b.String()
+}
+
+// TempoCode is a tempo in beats per minute from a point in time
+// A tempo of 0 is beat-free audio, and 1 is a single beat followed by
+// beat-free audio
+type TempoCode struct {
+	Time uint32
+	BPM  uint16
+}
+
+// SyncTempoFrame represents frames that contain the tempo of the audio over
+// time
+type SyncTempoFrame struct {
+	FrameHead
+	format TimestampFormat
+	codes  []TempoCode
+}
+
+func tempoCodesSize(codes []TempoCode) (int, error) {
+	size := 0
+	for _, code := range codes {
+		if code.BPM > maxTempo {
+			return 0, fmt.Errorf("tempo: %d BPM is more than %d", code.BPM, maxTempo)
+		}
+
+		if code.BPM >= tempoExtended {
+			size++
+		}
+
+		size += 1 + timestampSize
+	}
+
+	return size, nil
+}
+
+// Creates a synchronized tempo frame with timestamps in milliseconds
+func NewSyncTempoFrame(ft FrameType, codes []TempoCode) (*SyncTempoFrame, error) {
+	size, err := tempoCodesSize(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(1 + size),
+	}
+
+	return &SyncTempoFrame{head, TimestampMilliseconds, append([]TempoCode(nil), codes...)}, nil
+}
+
+func ParseSyncTempoFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadSyncTempoFrame(head, data))
+}
+
+func ReadSyncTempoFrame(head FrameHead, data []byte) (Framer, error) {
+	f := &SyncTempoFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	format, err := rd.ReadByte()
+	if err != nil {
+		return nil, truncatedError(len(data), 1)
+	}
+
+	f.format = TimestampFormat(format)
+
+	for rd.Len() > 0 {
+		var code TempoCode
+
+		bpm, _ := rd.ReadByte()
+		code.BPM = uint16(bpm)
+
+		if bpm == tempoExtended {
+			extra, err := rd.ReadByte()
+			if err != nil {
+				return nil, truncatedError(len(data), len(data)+1)
+			}
+
+			code.BPM += uint16(extra)
+		}
+
+		if code.Time, err = readTimestamp(rd); err != nil {
+			return nil, err
+		}
+
+		f.codes = append(f.codes, code)
+	}
+
+	return f, nil
+}
+
+func (f SyncTempoFrame) TimestampFormat() TimestampFormat {
+	return f.format
+}
+
+// Sets the unit of the timestamps
+// The timestamps themselves are not converted
+func (f *SyncTempoFrame) SetTimestampFormat(format TimestampFormat) {
+	f.format = format
+	f.changeSize(0)
+}
+
+// Tempo codes in the order of the frame
+func (f SyncTempoFrame) TempoCodes() []TempoCode {
+	return append([]TempoCode(nil), f.codes...)
+}
+
+func (f *SyncTempoFrame) SetTempoCodes(codes []TempoCode) error {
+	size, err := tempoCodesSize(codes)
+	if err != nil {
+		return err
+	}
+
+	f.changeSize(1 + size - int(f.size))
+	f.codes = append([]TempoCode(nil), codes...)
+	return nil
+}
+
+func (f SyncTempoFrame) String() string {
+	codes := make([]string, len(f.codes))
+	for i, code := range f.codes {
+		codes[i] = fmt.Sprintf("%d: %d BPM", code.Time, code.BPM)
+	}
+
+	return strings.Join(codes, "\n")
+}
+
+func (f SyncTempoFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteByte(byte(f.format)); err != nil {
+		return bytes
+	}
+
+	for _, code := range f.codes {
+		if code.BPM >= tempoExtended {
+			if err = wr.WriteByte(tempoExtended); err != nil {
+				return bytes
+			}
+
+			if err = wr.WriteByte(byte(code.BPM - tempoExtended)); err != nil {
+				return bytes
+			}
+		} else if err = wr.WriteByte(byte(code.BPM)); err != nil {
+			return bytes
+		}
+
+		if _, err = wr.Write(encodedbytes.NormBytes(code.Time)); err != nil {
+			return bytes
+		}
+	}
+
+	return bytes
+}
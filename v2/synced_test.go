@@ -0,0 +1,142 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSyncLyricsFrame(t *testing.T) {
+	data := []byte{
+		0, 'e', 'n', 'g', 2, 1, 'K', 0,
+		'H', 'e', 'l', 'l', 'o', 0, 0, 0, 0x03, 0xe8,
+		'\n', 'W', 'o', 'r', 'l', 'd', 0, 0, 0, 0x07, 0xd0,
+	}
+
+	frame, err := ReadSyncLyricsFrame(FrameHead{FrameType: V23FrameTypeMap["SYLT"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*SyncLyricsFrame)
+	expected := []SyncedText{{1000, "Hello"}, {2000, "\nWorld"}}
+	if f.Language() != "eng" || f.TimestampFormat() != TimestampMilliseconds || f.ContentType() != LyricsText ||
+		f.Descriptor() != "K" || !reflect.DeepEqual(f.Entries(), expected) {
+		t.Errorf("ReadSyncLyricsFrame incorrect frame, %v", f)
+	}
+
+	if b := f.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	if err := f.SetEntries(append(expected, SyncedText{3000, "世界"})); err == nil {
+		t.Errorf("SetEntries with text that is not ISO-8859-1 succeeded")
+	}
+
+	if err := f.SetEncoding("UTF-16"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.SetEntries(append(expected, SyncedText{3000, "世界"})); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ReadSyncLyricsFrame(f.FrameHead, f.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entries := parsed.(*SyncLyricsFrame).Entries(); len(entries) != 3 || entries[2].Text != "世界" {
+		t.Errorf("UTF-16 frame has incorrect entries %v", entries)
+	}
+}
+
+func TestSyncLyricsFrameByteOrderMarks(t *testing.T) {
+	// UTF-16 without byte order marks is read as big endian, and written with
+	// them
+	data := []byte("\x01eng\x02\x01\x00\x00\x00H\x00\x00\x00\x00\x03\xe8")
+	frame, err := ReadSyncLyricsFrame(FrameHead{FrameType: V23FrameTypeMap["SYLT"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*SyncLyricsFrame)
+	expected := []byte("\x01eng\x02\x01\x00\x00\xff\xfeH\x00\x00\x00\x00\x00\x03\xe8")
+	if b := f.Bytes(); int(f.Size()) != len(expected) || !bytes.Equal(b, expected) {
+		t.Errorf("Bytes produces %v, expected %v", b, expected)
+	}
+}
+
+func TestLRC(t *testing.T) {
+	lrc := "[ar:Someone]\n[offset:+500]\n[00:12.34][01:00.00]Chorus\n[00:05.5]Verse\n\n"
+
+	entries, err := ParseLRC(lrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []SyncedText{{5000, "Verse"}, {11840, "Chorus"}, {59500, "Chorus"}}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("ParseLRC incorrect entries %v", entries)
+	}
+
+	if _, err := ParseLRC("[00:75.00]Late"); err == nil {
+		t.Errorf("ParseLRC accepted a timestamp past 59 seconds")
+	}
+
+	tag := NewTag(4)
+	f, err := NewSyncLyricsFrame(V24FrameTypeMap["SYLT"], "eng", "", entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag.AddFrames(f)
+
+	parsed, err := ReadTag(bytes.NewReader(tag.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := parsed.Frame("SYLT").(*SyncLyricsFrame).LRC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "[00:05.00]Verse\n[00:11.84]Chorus\n[00:59.50]Chorus\n"; out != expected {
+		t.Errorf("LRC produced %q, expected %q", out, expected)
+	}
+
+	f.SetTimestampFormat(TimestampMPEGFrames)
+	if _, err := f.LRC(); err == nil {
+		t.Errorf("LRC converted timestamps in MPEG frames")
+	}
+}
+
+func TestSyncTempoFrame(t *testing.T) {
+	data := []byte{2, 120, 0, 0, 0, 0, 0xff, 45, 0, 0, 0x27, 0x10}
+
+	frame, err := ReadSyncTempoFrame(FrameHead{FrameType: V23FrameTypeMap["SYTC"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*SyncTempoFrame)
+	expected := []TempoCode{{0, 120}, {10000, 300}}
+	if !reflect.DeepEqual(f.TempoCodes(), expected) {
+		t.Errorf("ReadSyncTempoFrame incorrect codes %v", f.TempoCodes())
+	}
+
+	if b := f.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	if err := f.SetTempoCodes([]TempoCode{{0, 511}}); err == nil {
+		t.Errorf("SetTempoCodes accepted a tempo past %d BPM", maxTempo)
+	}
+
+	if err := f.SetTempoCodes(expected[:1]); err != nil || f.Size() != 6 {
+		t.Errorf("SetTempoCodes incorrect size %d, %v", f.Size(), err)
+	}
+}
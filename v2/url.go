@@ -0,0 +1,305 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"net/url"
+	"strings"
+)
+
+// URLs are always ISO-8859-1
+const urlEncoding = 0
+
+// Checks that a URL can be written to a URL frame
+func validateURL(u string) error {
+	if strings.ContainsRune(u, 0) {
+		return fmt.Errorf("%w: %q contains a null character", ErrInvalidURL, u)
+	}
+
+	if _, err := encodedbytes.Encoders[urlEncoding].ConvertString(u); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	if _, err := url.Parse(u); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	return nil
+}
+
+// Number of bytes of a URL in a URL frame
+func urlSize(u string) int {
+	encoded, _ := encodedbytes.Encoders[urlEncoding].ConvertString(u)
+	return len(encoded)
+}
+
+// Reads the URL that makes up the rest of a URL frame
+// Some taggers end the URL with null characters, which are dropped, and the
+// number of dropped bytes is returned with the URL
+func readURL(rd *encodedbytes.Reader) (string, int, error) {
+	data, err := rd.ReadRest()
+	if err != nil {
+		return "", 0, err
+	}
+
+	trimmed := bytes.TrimRight(data, "\x00")
+
+	u, err := encodedbytes.Decoders[urlEncoding].ConvertString(string(trimmed))
+	if err != nil {
+		return "", 0, err
+	}
+
+	return u, len(data) - len(trimmed), nil
+}
+
+// URLFrame represents frames that link to a web page
+type URLFrame struct {
+	FrameHead
+	url string
+}
+
+func NewURLFrame(ft FrameType, u string) (*URLFrame, error) {
+	if err := validateURL(u); err != nil {
+		return nil, err
+	}
+
+	head := FrameHead{
+		FrameType: ft,
+		size:      uint32(urlSize(u)),
+	}
+
+	return &URLFrame{head, u}, nil
+}
+
+func ParseURLFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadURLFrame(head, data))
+}
+
+func ReadURLFrame(head FrameHead, data []byte) (Framer, error) {
+	f := &URLFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	u, dropped, err := readURL(rd)
+	if err != nil {
+		return nil, err
+	}
+
+	f.url = u
+	f.size = uint32(len(data) - dropped)
+
+	return f, nil
+}
+
+func (f URLFrame) URL() string {
+	return f.url
+}
+
+func (f *URLFrame) SetURL(u string) error {
+	if err := validateURL(u); err != nil {
+		return err
+	}
+
+	f.changeSize(urlSize(u) - urlSize(f.url))
+	f.url = u
+	return nil
+}
+
+func (f URLFrame) String() string {
+	return f.url
+}
+
+func (f URLFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteString(f.url, urlEncoding); err != nil {
+		return bytes
+	}
+
+	return bytes
+}
+
+// UserURLFrame represents user defined URL frames, which have a description
+// of the link
+type UserURLFrame struct {
+	FrameHead
+	encoding    byte
+	description string
+	url         string
+}
+
+func NewUserURLFrame(ft FrameType, description, u string) (*UserURLFrame, error) {
+	if err := validateURL(u); err != nil {
+		return nil, err
+	}
+
+	f := &UserURLFrame{
+		FrameHead:   FrameHead{FrameType: ft},
+		description: description,
+		url:         u,
+	}
+
+	// The description is ISO-8859-1 unless it needs UTF-16
+	if _, err := encodedbytes.Encoders[f.encoding].ConvertString(description); err != nil {
+		f.encoding = encodedbytes.IndexForEncoding("UTF-16")
+	}
+
+	encoded, err := encodedbytes.Encoders[f.encoding].ConvertString(description)
+	if err != nil {
+		return nil, err
+	}
+
+	f.size = uint32(1 + len(encoded) + encodedbytes.EncodingNullLengthForIndex(f.encoding) + urlSize(u))
+
+	return f, nil
+}
+
+func ParseUserURLFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadUserURLFrame(head, data))
+}
+
+func ReadUserURLFrame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := &UserURLFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	if f.encoding, err = readEncoding(rd); err != nil {
+		return nil, err
+	}
+
+	if f.description, err = rd.ReadNullTermString(f.encoding); err != nil {
+		return nil, err
+	}
+
+	u, dropped, err := readURL(rd)
+	if err != nil {
+		return nil, err
+	}
+
+	f.url = u
+	f.size = uint32(len(data) - dropped)
+
+	return f, nil
+}
+
+func (f UserURLFrame) Encoding() string {
+	return encodedbytes.EncodingForIndex(f.encoding)
+}
+
+func (f *UserURLFrame) SetEncoding(encoding string) error {
+	i := encodedbytes.IndexForEncoding(encoding)
+	if encodedbytes.EncodingForIndex(i) != encoding {
+		return errors.New("encoding: invalid encoding")
+	}
+
+	diff, err := encodedbytes.EncodedDiff(i, f.description, f.encoding, f.description)
+	if err != nil {
+		return err
+	}
+
+	newNullLength := encodedbytes.EncodingNullLengthForIndex(i)
+	oldNullLength := encodedbytes.EncodingNullLengthForIndex(f.encoding)
+
+	f.changeSize(diff + newNullLength - oldNullLength)
+	f.encoding = i
+	return nil
+}
+
+func (f UserURLFrame) Description() string {
+	return f.description
+}
+
+func (f *UserURLFrame) SetDescription(description string) error {
+	diff, err := encodedbytes.EncodedDiff(f.encoding, description, f.encoding, f.description)
+	if err != nil {
+		return err
+	}
+
+	f.changeSize(diff)
+	f.description = description
+	return nil
+}
+
+func (f UserURLFrame) URL() string {
+	return f.url
+}
+
+func (f *UserURLFrame) SetURL(u string) error {
+	if err := validateURL(u); err != nil {
+		return err
+	}
+
+	f.changeSize(urlSize(u) - urlSize(f.url))
+	f.url = u
+	return nil
+}
+
+func (f UserURLFrame) String() string {
+	return fmt.Sprintf("%s: %s", f.description, f.url)
+}
+
+func (f UserURLFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteByte(f.encoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteNullTermString(f.description, f.encoding); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteString(f.url, urlEncoding); err != nil {
+		return bytes
+	}
+
+	return bytes
+}
+
+// Official web page of the artist
+func (t Tag) ArtistURL() string {
+	return t.urlFrameURL(t.commonMap["ArtistURL"])
+}
+
+func (t *Tag) SetArtistURL(u string) error {
+	return t.setURLFrameURL(t.commonMap["ArtistURL"], u)
+}
+
+// Official web page of the source of the audio, such as the movie it is from
+func (t Tag) SourceURL() string {
+	return t.urlFrameURL(t.commonMap["SourceURL"])
+}
+
+func (t *Tag) SetSourceURL(u string) error {
+	return t.setURLFrameURL(t.commonMap["SourceURL"], u)
+}
+
+func (t Tag) urlFrameURL(ft FrameType) string {
+	if f, ok := t.Frame(ft.Id()).(*URLFrame); ok {
+		return f.URL()
+	}
+
+	return ""
+}
+
+func (t *Tag) setURLFrameURL(ft FrameType, u string) error {
+	if f, ok := t.Frame(ft.Id()).(*URLFrame); ok {
+		return f.SetURL(u)
+	}
+
+	f, err := NewURLFrame(ft, u)
+	if err != nil {
+		return err
+	}
+
+	t.AddFrames(f)
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestURLFrame(t *testing.T) {
+	data := []byte("http://example.com/\x00\x00")
+	head := FrameHead{FrameType: V23FrameTypeMap["WOAR"], size: uint32(len(data))}
+
+	frame, err := ReadURLFrame(head, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*URLFrame)
+	if f.URL() != "http://example.com/" {
+		t.Errorf("ReadURLFrame incorrect URL %q", f.URL())
+	}
+
+	// Trailing null characters are not written back
+	expected := []byte("http://example.com/")
+	if b := f.Bytes(); !bytes.Equal(b, expected) || int(f.Size()) != len(expected) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", expected, b)
+	}
+
+	for _, u := range []string{"http://a\x00b", "http://例え.jp/", "http://[::1"} {
+		if err := f.SetURL(u); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("SetURL(%q) error %v, expected ErrInvalidURL", u, err)
+		}
+	}
+
+	if f.URL() != "http://example.com/" {
+		t.Errorf("invalid URL changed frame to %q", f.URL())
+	}
+}
+
+func TestUserURLFrame(t *testing.T) {
+	data := []byte("\x00Shop\x00http://shop.example.com/\x00")
+	head := FrameHead{FrameType: V23FrameTypeMap["WXXX"], size: uint32(len(data))}
+
+	frame, err := ReadUserURLFrame(head, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*UserURLFrame)
+	if f.Description() != "Shop" || f.URL() != "http://shop.example.com/" {
+		t.Errorf("ReadUserURLFrame incorrect frame, %v", f)
+	}
+
+	expected := data[:len(data)-1]
+	if b := f.Bytes(); !bytes.Equal(b, expected) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", expected, b)
+	}
+
+	// Descriptions that are not ISO-8859-1 are UTF-16, but the URL is not
+	f, err = NewUserURLFrame(V23FrameTypeMap["WXXX"], "お店", "http://shop.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Encoding() != "UTF-16" {
+		t.Errorf("NewUserURLFrame incorrect encoding %s", f.Encoding())
+	}
+
+	b := f.Bytes()
+	if len(b) != int(f.Size()) || !bytes.HasSuffix(b, []byte("\x00\x00http://shop.example.com/")) {
+		t.Errorf("NewUserURLFrame incorrect bytes %v", b)
+	}
+}
+
+func TestTagURLs(t *testing.T) {
+	for _, version := range []byte{2, 3, 4} {
+		tag := NewTag(version)
+		if err := tag.SetArtistURL("http://artist.example.com/"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tag.SetSourceURL("http://source.example.com/"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tag.SetArtistURL("http://artist.example.com/about"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tag.SetSourceURL("http://source.example.com/\x00"); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("v2.%d SetSourceURL error %v, expected ErrInvalidURL", version, err)
+		}
+
+		parsed, err := ReadTag(bytes.NewReader(tag.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if u := parsed.ArtistURL(); u != "http://artist.example.com/about" {
+			t.Errorf("v2.%d incorrect artist URL %q", version, u)
+		}
+
+		if u := parsed.SourceURL(); u != "http://source.example.com/" {
+			t.Errorf("v2.%d incorrect source URL %q", version, u)
+		}
+
+		if n := len(parsed.AllFrames()); n != 2 {
+			t.Errorf("v2.%d tag has %d frames", version, n)
+		}
+	}
+}
@@ -6,3 +6,18 @@ package v2
 func isBitSet(flag, index byte) bool {
 	return flag&(1<<index) != 0
 }
+
+// Frame IDs are made of capital letters and digits
+func isValidFrameId(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	for _, c := range id {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,680 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mikkyang/id3-go/encodedbytes"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Channel is an audio channel of a volume adjustment
+type Channel byte
+
+const (
+	ChannelOther Channel = iota
+	ChannelMaster
+	ChannelFrontRight
+	ChannelFrontLeft
+	ChannelBackRight
+	ChannelBackLeft
+	ChannelFrontCentre
+	ChannelBackCentre
+	ChannelSubwoofer
+)
+
+// Volumes and peaks are kept in at most 64 bits
+const maxVolumeBits = 64
+
+var (
+	channelDescriptions = [...]string{
+		"Other",
+		"Master volume",
+		"Front right",
+		"Front left",
+		"Back right",
+		"Back left",
+		"Front centre",
+		"Back centre",
+		"Subwoofer",
+	}
+
+	// Channels of ID3v2.2 and ID3v2.3 volume adjustment frames, in the order
+	// of their increment flags
+	relativeVolumeChannels = []Channel{
+		ChannelFrontRight,
+		ChannelFrontLeft,
+		ChannelBackRight,
+		ChannelBackLeft,
+		ChannelFrontCentre,
+		ChannelSubwoofer,
+	}
+
+	// Channels of ID3v2.2 and ID3v2.3 volume adjustment frames are written in
+	// groups, with the volumes of a group before their peaks
+	relativeVolumeGroups = [][2]int{{0, 2}, {2, 4}, {4, 5}, {5, 6}}
+)
+
+func (c Channel) String() string {
+	if int(c) < len(channelDescriptions) {
+		return channelDescriptions[c]
+	}
+
+	return "Unknown"
+}
+
+// Number of bytes of a volume with a number of bits
+func volumeSize(bits byte) int {
+	return (int(bits) + 7) / 8
+}
+
+// Checks that a value fits in a number of bits
+func fitsBits(v uint64, bits byte) bool {
+	return bits >= maxVolumeBits || v>>bits == 0
+}
+
+func readVolume(rd *encodedbytes.Reader, bits byte) (uint64, error) {
+	n := volumeSize(bits)
+	b, err := rd.ReadNumBytes(n)
+	if err != nil {
+		return 0, truncatedError(rd.Len(), n)
+	}
+
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+
+	return v, nil
+}
+
+func volumeBytes(v uint64, bits byte) []byte {
+	b := make([]byte, volumeSize(bits))
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+
+	return b
+}
+
+// VolumeAdjustment is the volume adjustment and peak volume of a channel in an
+// ID3v2.2 or ID3v2.3 volume adjustment frame
+type VolumeAdjustment struct {
+	Channel    Channel
+	Adjustment int64
+	Peak       uint64
+}
+
+// RelativeVolumeFrame represents ID3v2.2 and ID3v2.3 relative volume
+// adjustment frames
+// The number of bits of the adjustments and peaks is the same for every
+// channel
+type RelativeVolumeFrame struct {
+	FrameHead
+	bits        byte
+	adjustments []VolumeAdjustment
+}
+
+func NewRelativeVolumeFrame(ft FrameType, bits byte, adjustments []VolumeAdjustment) (*RelativeVolumeFrame, error) {
+	f := &RelativeVolumeFrame{
+		FrameHead: FrameHead{FrameType: ft},
+		bits:      bits,
+	}
+
+	adjustments, err := f.arrange(bits, adjustments)
+	if err != nil {
+		return nil, err
+	}
+
+	f.adjustments = adjustments
+	f.size = uint32(f.dataSize())
+	return f, nil
+}
+
+func ParseRelativeVolumeFrame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadRelativeVolumeFrame(head, data))
+}
+
+func ReadRelativeVolumeFrame(head FrameHead, data []byte) (Framer, error) {
+	f := &RelativeVolumeFrame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	flags, err := rd.ReadByte()
+	if err != nil {
+		return nil, truncatedError(len(data), 2)
+	}
+
+	if f.bits, err = rd.ReadByte(); err != nil {
+		return nil, truncatedError(len(data), 2)
+	}
+
+	if f.bits == 0 || f.bits > maxVolumeBits {
+		return nil, fmt.Errorf("volume: %d bits are not supported", f.bits)
+	}
+
+	n := volumeSize(f.bits)
+	for i, group := range relativeVolumeGroups {
+		channels := group[1] - group[0]
+		if i > 0 && rd.Len() < 2*channels*n {
+			break
+		}
+
+		adjustments := make([]VolumeAdjustment, channels)
+		for j := range adjustments {
+			index := group[0] + j
+			v, err := readVolume(rd, f.bits)
+			if err != nil {
+				return nil, err
+			}
+
+			adjustments[j].Channel = relativeVolumeChannels[index]
+			adjustments[j].Adjustment = int64(v)
+			if !isBitSet(flags, byte(index)) {
+				adjustments[j].Adjustment = -adjustments[j].Adjustment
+			}
+		}
+
+		for j := range adjustments {
+			if adjustments[j].Peak, err = readVolume(rd, f.bits); err != nil {
+				return nil, err
+			}
+		}
+
+		f.adjustments = append(f.adjustments, adjustments...)
+	}
+
+	f.size = uint32(f.dataSize())
+	return f, nil
+}
+
+// Orders adjustments by channel, adding the channels that are written along
+// with them
+func (f RelativeVolumeFrame) arrange(bits byte, adjustments []VolumeAdjustment) ([]VolumeAdjustment, error) {
+	if bits == 0 || bits > maxVolumeBits {
+		return nil, fmt.Errorf("volume: %d bits are not supported", bits)
+	}
+
+	channels := relativeVolumeChannels
+	if f.Id() == "RVA" {
+		channels = channels[:2]
+	}
+
+	arranged := make([]VolumeAdjustment, len(channels))
+	count := 2
+	for _, adjustment := range adjustments {
+		index := -1
+		for i, c := range channels {
+			if c == adjustment.Channel {
+				index = i
+			}
+		}
+
+		if index < 0 {
+			return nil, fmt.Errorf("volume: %s channel is not supported by %s frames", adjustment.Channel, f.Id())
+		}
+
+		magnitude := uint64(adjustment.Adjustment)
+		if adjustment.Adjustment < 0 {
+			magnitude = uint64(-adjustment.Adjustment)
+		}
+
+		if !fitsBits(magnitude, bits) || !fitsBits(adjustment.Peak, bits) {
+			return nil, fmt.Errorf("volume: %s channel does not fit in %d bits", adjustment.Channel, bits)
+		}
+
+		arranged[index] = adjustment
+		for _, group := range relativeVolumeGroups {
+			if index < group[1] {
+				if count < group[1] {
+					count = group[1]
+				}
+				break
+			}
+		}
+	}
+
+	for i := range arranged[:count] {
+		arranged[i].Channel = channels[i]
+	}
+
+	return arranged[:count], nil
+}
+
+// Number of bytes of the frame data
+func (f RelativeVolumeFrame) dataSize() int {
+	return 2 + 2*len(f.adjustments)*volumeSize(f.bits)
+}
+
+// Number of bits of the adjustments and peaks
+func (f RelativeVolumeFrame) Bits() byte {
+	return f.bits
+}
+
+func (f *RelativeVolumeFrame) SetBits(bits byte) error {
+	adjustments, err := f.arrange(bits, f.adjustments)
+	if err != nil {
+		return err
+	}
+
+	f.bits = bits
+	f.adjustments = adjustments
+	f.changeSize(f.dataSize() - int(f.size))
+	return nil
+}
+
+// Adjustments of the channels in the frame
+// Negative adjustments decrease the volume
+func (f RelativeVolumeFrame) Adjustments() []VolumeAdjustment {
+	return append([]VolumeAdjustment(nil), f.adjustments...)
+}
+
+// Sets the adjustments of the channels
+// Channels that are missing are written without adjustment when a later
+// channel needs them
+func (f *RelativeVolumeFrame) SetAdjustments(adjustments []VolumeAdjustment) error {
+	adjustments, err := f.arrange(f.bits, adjustments)
+	if err != nil {
+		return err
+	}
+
+	f.adjustments = adjustments
+	f.changeSize(f.dataSize() - int(f.size))
+	return nil
+}
+
+func (f RelativeVolumeFrame) String() string {
+	adjustments := make([]string, len(f.adjustments))
+	for i, adjustment := range f.adjustments {
+		adjustments[i] = fmt.Sprintf("%s: %d (peak %d)", adjustment.Channel, adjustment.Adjustment, adjustment.Peak)
+	}
+
+	return strings.Join(adjustments, "\n")
+}
+
+func (f RelativeVolumeFrame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	var flags byte
+	for i, adjustment := range f.adjustments {
+		if adjustment.Adjustment >= 0 {
+			flags |= 1 << uint(i)
+		}
+	}
+
+	if err = wr.WriteByte(flags); err != nil {
+		return bytes
+	}
+
+	if err = wr.WriteByte(f.bits); err != nil {
+		return bytes
+	}
+
+	for _, group := range relativeVolumeGroups {
+		if group[1] > len(f.adjustments) {
+			break
+		}
+
+		adjustments := f.adjustments[group[0]:group[1]]
+		for _, adjustment := range adjustments {
+			magnitude := uint64(adjustment.Adjustment)
+			if adjustment.Adjustment < 0 {
+				magnitude = uint64(-adjustment.Adjustment)
+			}
+
+			if _, err = wr.Write(volumeBytes(magnitude, f.bits)); err != nil {
+				return bytes
+			}
+		}
+
+		for _, adjustment := range adjustments {
+			if _, err = wr.Write(volumeBytes(adjustment.Peak, f.bits)); err != nil {
+				return bytes
+			}
+		}
+	}
+
+	return bytes
+}
+
+// ChannelVolume is the volume adjustment and peak volume of a channel in an
+// ID3v2.4 volume adjustment frame
+// The adjustment is in 1/512 dB, and the peak has its own number of bits
+type ChannelVolume struct {
+	Channel    Channel
+	Adjustment int16
+	PeakBits   byte
+	Peak       uint64
+}
+
+// Volume adjustment in dB
+func (c ChannelVolume) Gain() float64 {
+	return float64(c.Adjustment) / 512
+}
+
+// Peak volume as a fraction of full scale
+func (c ChannelVolume) PeakAmplitude() float64 {
+	if c.PeakBits == 0 {
+		return 0
+	}
+
+	return float64(c.Peak) / math.Exp2(float64(c.PeakBits-1))
+}
+
+func (c ChannelVolume) size() int {
+	return 1 + 2 + 1 + volumeSize(c.PeakBits)
+}
+
+func (c ChannelVolume) validate() error {
+	if c.PeakBits > maxVolumeBits {
+		return fmt.Errorf("volume: %d bits are not supported", c.PeakBits)
+	}
+
+	if !fitsBits(c.Peak, c.PeakBits) {
+		return fmt.Errorf("volume: %s channel peak does not fit in %d bits", c.Channel, c.PeakBits)
+	}
+
+	return nil
+}
+
+// RVA2Frame represents ID3v2.4 relative volume adjustment frames
+type RVA2Frame struct {
+	FrameHead
+	identification string
+	channels       []ChannelVolume
+}
+
+func NewRVA2Frame(ft FrameType, identification string, channels []ChannelVolume) (*RVA2Frame, error) {
+	// The frame starts with the null terminator of an empty identification
+	f := &RVA2Frame{FrameHead: FrameHead{FrameType: ft, size: 1}}
+	if err := f.SetIdentification(identification); err != nil {
+		return nil, err
+	}
+
+	if err := f.SetChannels(channels); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func ParseRVA2Frame(head FrameHead, data []byte) Framer {
+	return parsedFrame(ReadRVA2Frame(head, data))
+}
+
+func ReadRVA2Frame(head FrameHead, data []byte) (Framer, error) {
+	var err error
+	f := &RVA2Frame{FrameHead: head}
+	rd := encodedbytes.NewReader(data)
+
+	if f.identification, err = rd.ReadNullTermString(encodedbytes.NativeEncoding); err != nil {
+		return nil, err
+	}
+
+	for rd.Len() > 0 {
+		var c ChannelVolume
+
+		b, err := rd.ReadNumBytes(4)
+		if err != nil {
+			return nil, truncatedError(rd.Len(), 4)
+		}
+
+		c.Channel = Channel(b[0])
+		c.Adjustment = int16(uint16(b[1])<<8 | uint16(b[2]))
+		c.PeakBits = b[3]
+
+		if err = c.validate(); err != nil {
+			return nil, err
+		}
+
+		if c.Peak, err = readVolume(rd, c.PeakBits); err != nil {
+			return nil, err
+		}
+
+		f.channels = append(f.channels, c)
+	}
+
+	return f, nil
+}
+
+// Identification of the situation or device the adjustment is for, such as
+// "track" or "album"
+func (f RVA2Frame) Identification() string {
+	return f.identification
+}
+
+func (f *RVA2Frame) SetIdentification(identification string) error {
+	if _, err := encodedbytes.Encoders[encodedbytes.NativeEncoding].ConvertString(identification); err != nil {
+		return err
+	}
+
+	f.changeSize(len(identification) - len(f.identification))
+	f.identification = identification
+	return nil
+}
+
+func (f RVA2Frame) Channels() []ChannelVolume {
+	return append([]ChannelVolume(nil), f.channels...)
+}
+
+// Volume of a channel in the frame
+func (f RVA2Frame) Channel(channel Channel) (ChannelVolume, bool) {
+	for _, c := range f.channels {
+		if c.Channel == channel {
+			return c, true
+		}
+	}
+
+	return ChannelVolume{}, false
+}
+
+func (f *RVA2Frame) SetChannels(channels []ChannelVolume) error {
+	diff := 0
+	for _, c := range channels {
+		if err := c.validate(); err != nil {
+			return err
+		}
+
+		diff += c.size()
+	}
+
+	for _, c := range f.channels {
+		diff -= c.size()
+	}
+
+	f.changeSize(diff)
+	f.channels = append([]ChannelVolume(nil), channels...)
+	return nil
+}
+
+func (f RVA2Frame) String() string {
+	channels := make([]string, len(f.channels))
+	for i, c := range f.channels {
+		channels[i] = fmt.Sprintf("%s: %+.2f dB (peak %g)", c.Channel, c.Gain(), c.PeakAmplitude())
+	}
+
+	return fmt.Sprintf("%s:\n%s", f.identification, strings.Join(channels, "\n"))
+}
+
+func (f RVA2Frame) Bytes() []byte {
+	var err error
+	bytes := make([]byte, f.Size())
+	wr := encodedbytes.NewWriter(bytes)
+
+	if err = wr.WriteNullTermString(f.identification, encodedbytes.NativeEncoding); err != nil {
+		return bytes
+	}
+
+	for _, c := range f.channels {
+		head := []byte{byte(c.Channel), byte(uint16(c.Adjustment) >> 8), byte(c.Adjustment), c.PeakBits}
+		if _, err = wr.Write(head); err != nil {
+			return bytes
+		}
+
+		if _, err = wr.Write(volumeBytes(c.Peak, c.PeakBits)); err != nil {
+			return bytes
+		}
+	}
+
+	return bytes
+}
+
+const (
+	replayGainTrack = "track"
+	replayGainAlbum = "album"
+
+	// Peaks of ReplayGain volume adjustment frames have 16 bits, with one
+	// bit for the integer part
+	replayGainPeakBits = 16
+)
+
+// ReplayGain is the ReplayGain adjustment of a track or an album
+type ReplayGain struct {
+	// Gain in dB
+	Gain float64
+
+	// Peak amplitude as a fraction of full scale
+	Peak float64
+}
+
+// ReplayGain of the track
+func (t Tag) TrackGain() (ReplayGain, bool) {
+	return t.replayGain(replayGainTrack)
+}
+
+// Sets the ReplayGain of the track
+func (t *Tag) SetTrackGain(rg ReplayGain) error {
+	return t.setReplayGain(replayGainTrack, rg)
+}
+
+// ReplayGain of the album
+func (t Tag) AlbumGain() (ReplayGain, bool) {
+	return t.replayGain(replayGainAlbum)
+}
+
+// Sets the ReplayGain of the album
+func (t *Tag) SetAlbumGain(rg ReplayGain) error {
+	return t.setReplayGain(replayGainAlbum, rg)
+}
+
+// Reads ReplayGain from user defined text frames, falling back to the master
+// volume of an ID3v2.4 volume adjustment frame
+func (t Tag) replayGain(kind string) (ReplayGain, bool) {
+	var rg ReplayGain
+
+	if gain, ok := t.userTextFrame(replayGainDescription(kind, "gain")); ok {
+		text := strings.TrimSpace(gain.Text())
+		if len(text) > 2 && strings.EqualFold(text[len(text)-2:], "dB") {
+			text = strings.TrimSpace(text[:len(text)-2])
+		}
+
+		var err error
+		if rg.Gain, err = strconv.ParseFloat(text, 64); err == nil {
+			if peak, ok := t.userTextFrame(replayGainDescription(kind, "peak")); ok {
+				rg.Peak, _ = strconv.ParseFloat(strings.TrimSpace(peak.Text()), 64)
+			}
+
+			return rg, true
+		}
+	}
+
+	if f := t.rva2Frame(kind); f != nil {
+		c, ok := f.Channel(ChannelMaster)
+		if !ok && len(f.channels) > 0 {
+			c, ok = f.channels[0], true
+		}
+
+		if ok {
+			return ReplayGain{c.Gain(), c.PeakAmplitude()}, true
+		}
+	}
+
+	return rg, false
+}
+
+// Writes ReplayGain to user defined text frames, and to an ID3v2.4 volume
+// adjustment frame for players that only read those
+func (t *Tag) setReplayGain(kind string, rg ReplayGain) error {
+	if err := t.setUserText(replayGainDescription(kind, "gain"), fmt.Sprintf("%+.2f dB", rg.Gain)); err != nil {
+		return err
+	}
+
+	if err := t.setUserText(replayGainDescription(kind, "peak"), fmt.Sprintf("%.6f", rg.Peak)); err != nil {
+		return err
+	}
+
+	if t.version != 4 {
+		return nil
+	}
+
+	adjustment := math.Round(rg.Gain * 512)
+	adjustment = math.Max(math.Min(adjustment, math.MaxInt16), math.MinInt16)
+
+	peak := math.Round(rg.Peak * math.Exp2(replayGainPeakBits-1))
+	peak = math.Max(math.Min(peak, math.MaxUint16), 0)
+
+	channels := []ChannelVolume{{ChannelMaster, int16(adjustment), replayGainPeakBits, uint64(peak)}}
+
+	if f := t.rva2Frame(kind); f != nil {
+		return f.SetChannels(channels)
+	}
+
+	f, err := NewRVA2Frame(V24FrameTypeMap["RVA2"], kind, channels)
+	if err != nil {
+		return err
+	}
+
+	t.AddFrames(f)
+	return nil
+}
+
+func replayGainDescription(kind, field string) string {
+	return strings.ToUpper("replaygain_" + kind + "_" + field)
+}
+
+func (t Tag) rva2Frame(identification string) *RVA2Frame {
+	for _, frame := range t.frames {
+		if f, ok := frame.(*RVA2Frame); ok && strings.EqualFold(f.Identification(), identification) {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// User defined text frame with a description, which is matched regardless of
+// case
+func (t Tag) userTextFrame(description string) (*DescTextFrame, bool) {
+	ft, ok := t.commonMap["UserText"]
+	if !ok {
+		return nil, false
+	}
+
+	for _, frame := range t.Frames(ft.Id()) {
+		if f, ok := frame.(*DescTextFrame); ok && strings.EqualFold(f.Description(), description) {
+			return f, true
+		}
+	}
+
+	return nil, false
+}
+
+func (t *Tag) setUserText(description, text string) error {
+	if f, ok := t.userTextFrame(description); ok {
+		return f.SetText(text)
+	}
+
+	ft, ok := t.commonMap["UserText"]
+	if !ok {
+		return errors.New("set user text: user defined text is not supported in this version")
+	}
+
+	t.AddFrames(NewDescTextFrame(ft, description, text))
+	return nil
+}
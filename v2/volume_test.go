@@ -0,0 +1,126 @@
+// Copyright 2013 Michael Yang. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+package v2
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestRelativeVolumeFrame(t *testing.T) {
+	data := []byte{0x01, 16, 0x01, 0x00, 0x02, 0x00, 0x7f, 0xff, 0x7f, 0xfe}
+
+	frame, err := ReadRelativeVolumeFrame(FrameHead{FrameType: V23FrameTypeMap["RVAD"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*RelativeVolumeFrame)
+	expected := []VolumeAdjustment{{ChannelFrontRight, 256, 0x7fff}, {ChannelFrontLeft, -512, 0x7ffe}}
+	if f.Bits() != 16 || !reflect.DeepEqual(f.Adjustments(), expected) {
+		t.Errorf("ReadRelativeVolumeFrame incorrect frame, %v", f)
+	}
+
+	if b := f.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	// The centre channel is written after the back channels
+	if err := f.SetAdjustments(append(expected, VolumeAdjustment{ChannelFrontCentre, 3, 4})); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ReadRelativeVolumeFrame(f.FrameHead, f.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adjustments := parsed.(*RelativeVolumeFrame).Adjustments()
+	if len(adjustments) != 5 || adjustments[4] != (VolumeAdjustment{ChannelFrontCentre, 3, 4}) || adjustments[2].Channel != ChannelBackRight {
+		t.Errorf("incorrect adjustments %v", adjustments)
+	}
+
+	if err := f.SetBits(8); err == nil {
+		t.Errorf("SetBits accepted bits that do not fit the peaks")
+	}
+
+	if _, err := NewRelativeVolumeFrame(V22FrameTypeMap["RVA"], 8, []VolumeAdjustment{{ChannelSubwoofer, 1, 1}}); err == nil {
+		t.Errorf("NewRelativeVolumeFrame accepted a subwoofer channel for ID3v2.2")
+	}
+}
+
+func TestRVA2Frame(t *testing.T) {
+	data := []byte{'t', 'r', 'a', 'c', 'k', 0, 1, 0xf3, 0x00, 16, 0x7e, 0x00, 8, 0x00, 0x10, 0}
+
+	frame, err := ReadRVA2Frame(FrameHead{FrameType: V24FrameTypeMap["RVA2"], size: uint32(len(data))}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := frame.(*RVA2Frame)
+	c, ok := f.Channel(ChannelMaster)
+	if f.Identification() != "track" || !ok || c.Gain() != -6.5 || c.PeakAmplitude() != 0.984375 {
+		t.Errorf("ReadRVA2Frame incorrect frame, %v", f)
+	}
+
+	if c, ok := f.Channel(ChannelSubwoofer); !ok || c.Gain() != 0.03125 || c.PeakBits != 0 {
+		t.Errorf("ReadRVA2Frame incorrect subwoofer channel %v", c)
+	}
+
+	if b := f.Bytes(); !bytes.Equal(b, data) {
+		t.Errorf("Bytes produces different byte slice, expected %v not %v", data, b)
+	}
+
+	if err := f.SetChannels([]ChannelVolume{{ChannelMaster, 0, 4, 16}}); err == nil {
+		t.Errorf("SetChannels accepted a peak that does not fit its bits")
+	}
+}
+
+func TestTagReplayGain(t *testing.T) {
+	for _, version := range []byte{2, 3, 4} {
+		tag := NewTag(version)
+		if err := tag.SetTrackGain(ReplayGain{-6.48, 0.988}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tag.SetAlbumGain(ReplayGain{-7.5, 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := tag.SetTrackGain(ReplayGain{-6.25, 0.5}); err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := ReadTag(bytes.NewReader(tag.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rg, ok := parsed.TrackGain(); !ok || rg != (ReplayGain{-6.25, 0.5}) {
+			t.Errorf("v2.%d incorrect track gain %v", version, rg)
+		}
+
+		if rg, ok := parsed.AlbumGain(); !ok || rg != (ReplayGain{-7.5, 1}) {
+			t.Errorf("v2.%d incorrect album gain %v", version, rg)
+		}
+
+		if n := len(parsed.Frames(parsed.commonMap["UserText"].Id())); n != 4 {
+			t.Errorf("v2.%d tag has %d user defined text frames", version, n)
+		}
+	}
+
+	// Volume adjustment frames are used without user defined text frames
+	tag := NewTag(4)
+	f, _ := NewRVA2Frame(V24FrameTypeMap["RVA2"], "Album", []ChannelVolume{{ChannelMaster, -1024, 16, 0x4000}})
+	tag.AddFrames(f)
+
+	if rg, ok := tag.AlbumGain(); !ok || rg != (ReplayGain{-2, 0.5}) {
+		t.Errorf("incorrect album gain from volume adjustment frame %v", rg)
+	}
+
+	if _, ok := tag.TrackGain(); ok {
+		t.Errorf("tag without track gain has a track gain")
+	}
+}